@@ -2498,6 +2498,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Print Hello World"
                 },
+                "deterministic": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "language": {
                     "type": "string",
                     "example": "en"
@@ -2513,6 +2517,12 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "pass_threshold": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 100
+                },
                 "reward": {
                     "type": "integer",
                     "example": 10
@@ -2549,6 +2559,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Print Hello World"
                 },
+                "deterministic": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "language": {
                     "type": "string",
                     "example": "en"
@@ -2565,6 +2579,12 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "pass_threshold": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 100
+                },
                 "reward": {
                     "type": "integer",
                     "example": 10
@@ -3039,6 +3059,7 @@ const docTemplate = `{
                 "lesson_uuid",
                 "modified_at",
                 "order_index",
+                "pass_threshold",
                 "reward",
                 "translation",
                 "type",
@@ -3054,6 +3075,10 @@ const docTemplate = `{
                 "deleted_at": {
                     "type": "string"
                 },
+                "deterministic": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "lesson_uuid": {
                     "type": "string"
                 },
@@ -3064,6 +3089,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "pass_threshold": {
+                    "type": "integer",
+                    "example": 100
+                },
                 "quiz_data": {
                     "$ref": "#/definitions/models.ExerciseQuizData"
                 },
@@ -3373,6 +3402,7 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "attempts",
+                "best_score",
                 "exercise_uuid",
                 "started_at",
                 "submission",
@@ -3384,6 +3414,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 0
                 },
+                "best_score": {
+                    "type": "integer",
+                    "example": 100
+                },
                 "completed_at": {
                     "type": "string"
                 },
@@ -60,10 +60,10 @@ func main() {
 	rmqClient := initializeRabbitMQ(cfg, log)
 	defer rmqClient.Close()
 
-	wsHub := websocket.NewHub(rmqClient, log, queries, pool)
+	wsHub := websocket.NewHub(rmqClient, log, queries, pool, redisClient)
 	go wsHub.Run()
 	go func() {
-		if err := wsHub.ListenToRabbitMQ(context.Background(), "codexec.results"); err != nil {
+		if err := wsHub.ListenToRabbitMQ(context.Background()); err != nil {
 			log.Errorf("Failed to listen to RabbitMQ: %v", err)
 		}
 	}()
@@ -23,6 +23,8 @@ type Config struct {
 	CmdPrefix        string        `env:"CMD_PREFIX"`
 	ExecutionTimeout time.Duration `env:"EXECUTION_TIMEOUT" envDefault:"10s"`
 	ShutdownTimeout  time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`
+	// MetricsAddr is where the metrics and health endpoints are served, empty to disable them.
+	MetricsAddr string `env:"METRICS_ADDR" envDefault:":9090"`
 }
 
 func Load() (Config, error) {
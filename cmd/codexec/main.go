@@ -4,13 +4,18 @@ import (
 	"codim/cmd/codexec/config"
 	"codim/pkg/executors"
 	"codim/pkg/executors/drivers"
+	"codim/pkg/metrics"
 	"codim/pkg/rabbitmq"
 	"codim/pkg/utils/logger"
 	"codim/pkg/worker"
 	"context"
+	"errors"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -30,7 +35,6 @@ func main() {
 	if err != nil {
 		logrus.Fatalf("Failed to initialize RabbitMQ: %v", err)
 	}
-	defer rmqClient.Close()
 
 	sigChan := setupSignalHandling()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -38,12 +42,37 @@ func main() {
 
 	go func() {
 		<-sigChan
-		logger.Info("Received shutdown signal, shutting down...")
+		logger.Infof("Received shutdown signal, draining running jobs for up to %s...", cfg.ShutdownTimeout)
 		cancel()
 	}()
 
+	registry := metrics.NewRegistry()
+	workerMetrics := worker.NewMetrics(registry)
+	workerMetrics.ObserveConnection(rmqClient)
+
+	// Ready while connected to RabbitMQ and not shutting down, so no new work is routed to a draining worker.
+	ready := func() bool {
+		return ctx.Err() == nil && rmqClient.State() == rabbitmq.StateConnected
+	}
+
+	var server *http.Server
+	if cfg.MetricsAddr != "" {
+		server = startMetricsServer(cfg.MetricsAddr, registry, ready, logger)
+	}
+
+	canceller := worker.NewCanceller(rmqClient, logger)
+	go func() {
+		if err := canceller.Listen(ctx); err != nil {
+			logger.Errorf("Stopped listening to job cancellations: %v", err)
+		}
+	}()
+
+	var wg sync.WaitGroup
 	for _, wCfg := range cfg.Workers {
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
+
 			driver, err := drivers.New(wCfg.Driver, cfg.CmdPrefix, logger)
 			if err != nil {
 				logger.Errorf("Failed to initialize driver %s: %v", wCfg.Driver, err)
@@ -52,10 +81,11 @@ func main() {
 
 			executorService := executors.New(driver, logger, cfg.ExecutionTimeout)
 
-			w := worker.New(rmqClient, executorService, logger, wCfg)
+			w := worker.New(rmqClient, executorService, logger, wCfg, cfg.ShutdownTimeout, canceller, workerMetrics)
 			logger.Infof("Starting worker for queue %s (driver: %s)", wCfg.Queue, wCfg.Driver)
 
-			// Start will handle reconnection internally
+			// Start resumes consuming after the client reconnects and only returns once ctx is done
+			// and the running jobs are drained, or the client is closed
 			if err := w.Start(ctx); err != nil {
 				logger.Errorf("Worker for queue %s stopped with error: %v", wCfg.Queue, err)
 			}
@@ -67,11 +97,76 @@ func main() {
 	}
 
 	<-ctx.Done()
+	waitForWorkers(&wg, cfg.ShutdownTimeout, logger)
+
+	// The connection stays open until the drained jobs have published their results.
+	if err := rmqClient.Close(); err != nil {
+		logger.Warnf("Failed to close RabbitMQ connection: %v", err)
+	}
+
+	if server != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Warnf("Failed to stop metrics server: %v", err)
+		}
+		cancelShutdown()
+	}
 	logger.Info("Application stopped")
 
 	os.Exit(0)
 }
 
+// waitForWorkers waits for the workers to drain their running jobs. Past the shutdown timeout the
+// consumers cancel and requeue what is left, which gets a few more seconds before exiting anyway.
+func waitForWorkers(wg *sync.WaitGroup, shutdownTimeout time.Duration, log *logger.Logger) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout + 5*time.Second):
+		log.Warn("Workers did not stop in time, exiting anyway")
+	}
+}
+
+// startMetricsServer serves the metrics, a liveness endpoint answering as long as the process runs,
+// and a readiness endpoint answering while the worker can take jobs.
+func startMetricsServer(addr string, registry *metrics.Registry, ready func() bool, log *logger.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("not ready"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Infof("Serving metrics and health checks on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Metrics server stopped: %v", err)
+		}
+	}()
+
+	return server
+}
+
 // initializeLogger creates and initializes the logger from configuration
 func initializeLogger(cfg config.Config) (*logger.Logger, error) {
 	log, err := logger.New(cfg.Logger)
@@ -3,6 +3,7 @@ package main
 import (
 	"codim/pkg/db"
 	"codim/pkg/executors/checkers"
+	"codim/pkg/executors/drivers/models"
 	"codim/pkg/fs"
 	"context"
 	"encoding/json"
@@ -54,13 +55,26 @@ type Translation struct {
 }
 
 type ExerciseSeed struct {
-	Type         db.ExerciseType
-	Reward       int16
-	Data         map[string]interface{}
-	Translations map[string]Translation
-	CodeChecker  *checkers.CodeChecker
-	IoChecker    *checkers.IOChecker
-	QuizChecker  *map[string]string
+	Type                db.ExerciseType
+	Reward              int16
+	PassThreshold       int16
+	Deterministic       bool
+	Data                map[string]interface{}
+	Translations        map[string]Translation
+	CodeChecker         *checkers.CodeChecker
+	IoChecker           *checkers.IOChecker
+	QuizChecker         *map[string]string
+	AstChecker          *checkers.ASTChecker
+	LintChecker         *checkers.LintChecker
+	PerformanceChecker  *checkers.PerformanceChecker
+	DifferentialChecker *checkers.DifferentialChecker
+	ServerChecker       *checkers.ServerChecker
+	ResultSetChecker    *checkers.ResultSetChecker
+	FileSystemChecker   *checkers.FileSystemChecker
+	TypeCheckChecker    *checkers.TypeCheckChecker
+	Database            *models.Database
+	RunConfig           *models.RunConfig
+	Environment         string
 }
 
 type LessonSeed struct {
@@ -120,9 +134,10 @@ func seedCourse(ctx context.Context, queries *db.Queries) db.Course {
 				},
 				Exercises: []ExerciseSeed{
 					{
-						Type:   db.ExerciseTypeCode,
-						Reward: 10,
-						Data:   map[string]interface{}{"code": "print('Hello World')", "task": "Print Hello World"},
+						Type:          db.ExerciseTypeCode,
+						Reward:        10,
+						Deterministic: true,
+						Data:          map[string]interface{}{"code": "print('Hello World')", "task": "Print Hello World"},
 						Translations: map[string]Translation{
 							"en": {
 								Name:        "Hello World",
@@ -260,6 +275,12 @@ func seedCourse(ctx context.Context, queries *db.Queries) db.Course {
 							"en": {Name: "For Loop", Description: "Count to 5."},
 							"he": {Name: "לולאת For", Description: "ספור עד 5."},
 						},
+						AstChecker: &checkers.ASTChecker{
+							Rules: []checkers.ASTRule{
+								{Kind: checkers.ASTRuleRequire, Construct: "for", Message: "Use a for loop"},
+								{Kind: checkers.ASTRuleForbid, Construct: "while", Message: "Don't use a while loop"},
+							},
+						},
 					},
 					{
 						Type:   db.ExerciseTypeCode,
@@ -295,6 +316,9 @@ func seedCourse(ctx context.Context, queries *db.Queries) db.Course {
 							"en": {Name: "Define Function", Description: "Create a greet function."},
 							"he": {Name: "הגדר פונקציה", Description: "צור פונקציית greet."},
 						},
+						LintChecker: &checkers.LintChecker{
+							Required: false,
+						},
 					},
 					{
 						Type:   db.ExerciseTypeCode,
@@ -445,13 +469,20 @@ func seedCourse(ctx context.Context, queries *db.Queries) db.Course {
 				quizData = createQuizData()
 			}
 
+			passThreshold := eSeed.PassThreshold
+			if passThreshold == 0 {
+				passThreshold = 100
+			}
+
 			params := db.CreateExerciseParams{
-				LessonUuid: l.Uuid,
-				OrderIndex: int16(j + 1),
-				Reward:     eSeed.Reward,
-				Type:       eSeed.Type,
-				CodeData:   codeData,
-				QuizData:   quizData,
+				LessonUuid:    l.Uuid,
+				OrderIndex:    int16(j + 1),
+				Reward:        eSeed.Reward,
+				PassThreshold: passThreshold,
+				Deterministic: eSeed.Deterministic,
+				Type:          eSeed.Type,
+				CodeData:      codeData,
+				QuizData:      quizData,
 			}
 
 			if eSeed.CodeChecker != nil {
@@ -466,6 +497,49 @@ func seedCourse(ctx context.Context, queries *db.Queries) db.Course {
 				quizCheckerData, _ := json.Marshal(eSeed.QuizChecker)
 				params.QuizChecker = createRawMessage(quizCheckerData)
 			}
+			if eSeed.AstChecker != nil {
+				astCheckerData, _ := json.Marshal(eSeed.AstChecker)
+				params.AstChecker = createRawMessage(astCheckerData)
+			}
+			if eSeed.LintChecker != nil {
+				lintCheckerData, _ := json.Marshal(eSeed.LintChecker)
+				params.LintChecker = createRawMessage(lintCheckerData)
+			}
+			if eSeed.PerformanceChecker != nil {
+				performanceCheckerData, _ := json.Marshal(eSeed.PerformanceChecker)
+				params.PerformanceChecker = createRawMessage(performanceCheckerData)
+			}
+			if eSeed.DifferentialChecker != nil {
+				differentialCheckerData, _ := json.Marshal(eSeed.DifferentialChecker)
+				params.DifferentialChecker = createRawMessage(differentialCheckerData)
+			}
+			if eSeed.ServerChecker != nil {
+				serverCheckerData, _ := json.Marshal(eSeed.ServerChecker)
+				params.ServerChecker = createRawMessage(serverCheckerData)
+			}
+			if eSeed.ResultSetChecker != nil {
+				resultSetCheckerData, _ := json.Marshal(eSeed.ResultSetChecker)
+				params.ResultSetChecker = createRawMessage(resultSetCheckerData)
+			}
+			if eSeed.FileSystemChecker != nil {
+				fileSystemCheckerData, _ := json.Marshal(eSeed.FileSystemChecker)
+				params.FilesystemChecker = createRawMessage(fileSystemCheckerData)
+			}
+			if eSeed.TypeCheckChecker != nil {
+				typeCheckCheckerData, _ := json.Marshal(eSeed.TypeCheckChecker)
+				params.TypecheckChecker = createRawMessage(typeCheckCheckerData)
+			}
+			if eSeed.Database != nil {
+				databaseData, _ := json.Marshal(eSeed.Database)
+				params.Database = createRawMessage(databaseData)
+			}
+			if eSeed.RunConfig != nil {
+				runConfigData, _ := json.Marshal(eSeed.RunConfig)
+				params.RunConfig = createRawMessage(runConfigData)
+			}
+			if eSeed.Environment != "" {
+				params.Environment = &eSeed.Environment
+			}
 
 			e, err := queries.CreateExercise(ctx, params)
 			if err != nil {
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"codim/pkg/executors/drivers/models"
+	"codim/pkg/fs"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	resultCacheKeyPrefix = "api:result:"
+	resultCacheTTL       = 24 * time.Hour
+	// resultCacheVersion is part of every key. Bump it when the sandbox limits or the drivers
+	// change, since those decide the outcome of a run without being part of the request.
+	resultCacheVersion = 3
+)
+
+// ResultCache stores execution results so identical submissions don't need another sandbox run.
+type ResultCache struct {
+	redis  *redis.Client
+	logger Logger
+}
+
+type resultCacheKey struct {
+	Version int                     `json:"version"`
+	Driver  string                  `json:"driver"`
+	Request models.ExecutionRequest `json:"request"`
+}
+
+func NewResultCache(redis *redis.Client, logger Logger) *ResultCache {
+	return &ResultCache{
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// Key hashes everything that decides the outcome of a run: the driver, the normalized source tree,
+// the entry point, the checkers and their limits. The job ID is left out.
+func (c *ResultCache) Key(driver string, req models.ExecutionRequest) (string, error) {
+	req.JobID = uuid.Nil
+	req.Source = normalizeEntry(req.Source)
+
+	data, err := json.Marshal(resultCacheKey{
+		Version: resultCacheVersion,
+		Driver:  driver,
+		Request: req,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result cache key: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s%s", resultCacheKeyPrefix, hex.EncodeToString(sum[:])), nil
+}
+
+// GetResult returns the cached result for the key, if any.
+func (c *ResultCache) GetResult(ctx context.Context, key string) (models.ExecuteResponse, bool) {
+	cached, err := c.redis.Get(ctx, key).Result()
+	if err != nil {
+		if err != redis.Nil {
+			c.logger.Warnf("Failed to get cached result %s: %v", key, err)
+		}
+		return models.ExecuteResponse{}, false
+	}
+
+	var res models.ExecuteResponse
+	if err := json.Unmarshal([]byte(cached), &res); err != nil {
+		c.logger.Warnf("Failed to unmarshal cached result %s: %v", key, err)
+		return models.ExecuteResponse{}, false
+	}
+
+	return res, true
+}
+
+// SetResult stores a result under the key.
+func (c *ResultCache) SetResult(ctx context.Context, key string, res models.ExecuteResponse) error {
+	res.JobID = uuid.Nil
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, key, data, resultCacheTTL).Err()
+}
+
+// normalizeEntry sorts the children by name and unifies line endings,
+// so the same code saved by different editors hashes the same.
+func normalizeEntry(entry fs.Entry) fs.Entry {
+	normalized := fs.Entry{
+		Name:    entry.Name,
+		Content: strings.ReplaceAll(entry.Content, "\r\n", "\n"),
+	}
+
+	if len(entry.Children) > 0 {
+		normalized.Children = make([]fs.Entry, len(entry.Children))
+		for i, child := range entry.Children {
+			normalized.Children[i] = normalizeEntry(child)
+		}
+		sort.Slice(normalized.Children, func(i, j int) bool {
+			return normalized.Children[i].Name < normalized.Children[j].Name
+		})
+	}
+
+	return normalized
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"codim/pkg/executors/checkers"
+	"codim/pkg/executors/drivers/models"
+	"codim/pkg/fs"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func request(source fs.Entry) models.ExecutionRequest {
+	return models.ExecutionRequest{
+		JobID:      uuid.New(),
+		Source:     source,
+		EntryPoint: "main.py",
+	}
+}
+
+func TestKey(t *testing.T) {
+	c := NewResultCache(nil, nil)
+
+	key := func(driver string, req models.ExecutionRequest) string {
+		t.Helper()
+		k, err := c.Key(driver, req)
+		require.NoError(t, err)
+		return k
+	}
+
+	source := fs.Entry{Name: "root", Children: []fs.Entry{
+		{Name: "main.py", Content: "import util\r\nprint(util.x)\r\n"},
+		{Name: "util.py", Content: "x = 1\n"},
+	}}
+	reordered := fs.Entry{Name: "root", Children: []fs.Entry{
+		{Name: "util.py", Content: "x = 1\n"},
+		{Name: "main.py", Content: "import util\nprint(util.x)\n"},
+	}}
+
+	base := key("python", request(source))
+	require.Regexp(t, `^api:result:[0-9a-f]{64}$`, base)
+
+	// The job ID, the order of the files and their line endings don't change the outcome.
+	require.Equal(t, base, key("python", request(reordered)))
+
+	require.NotEqual(t, base, key("node", request(source)))
+
+	changed := fs.Entry{Name: "root", Children: []fs.Entry{
+		{Name: "main.py", Content: "import util\nprint(util.x)\n"},
+		{Name: "util.py", Content: "x = 2\n"},
+	}}
+	require.NotEqual(t, base, key("python", request(changed)))
+
+	withChecker := request(reordered)
+	withChecker.IOChecker = &checkers.IOChecker{Input: "1", ExpectedOutput: "1"}
+	require.NotEqual(t, base, key("python", withChecker))
+}
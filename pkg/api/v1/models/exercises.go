@@ -24,16 +24,18 @@ type ExerciseTranslationQuizDataQuestion struct {
 type ExerciseTranslationQuizData = map[string]ExerciseTranslationQuizDataQuestion
 
 type Exercise struct {
-	Uuid       uuid.UUID         `json:"uuid" binding:"required"`
-	CreatedAt  time.Time         `json:"created_at" binding:"required"`
-	ModifiedAt time.Time         `json:"modified_at" binding:"required"`
-	DeletedAt  *time.Time        `json:"deleted_at,omitempty"`
-	LessonUuid uuid.UUID         `json:"lesson_uuid" binding:"required"`
-	OrderIndex int16             `json:"order_index" binding:"required" example:"1"`
-	Reward     int16             `json:"reward" binding:"required" example:"10"`
-	Type       db.ExerciseType   `json:"type" binding:"required" example:"quiz"`
-	CodeData   *ExerciseCodeData `json:"code_data,omitempty"`
-	QuizData   *ExerciseQuizData `json:"quiz_data,omitempty"`
+	Uuid          uuid.UUID         `json:"uuid" binding:"required"`
+	CreatedAt     time.Time         `json:"created_at" binding:"required"`
+	ModifiedAt    time.Time         `json:"modified_at" binding:"required"`
+	DeletedAt     *time.Time        `json:"deleted_at,omitempty"`
+	LessonUuid    uuid.UUID         `json:"lesson_uuid" binding:"required"`
+	OrderIndex    int16             `json:"order_index" binding:"required" example:"1"`
+	Reward        int16             `json:"reward" binding:"required" example:"10"`
+	PassThreshold int16             `json:"pass_threshold" binding:"required" example:"100"`
+	Deterministic bool              `json:"deterministic" example:"false"`
+	Type          db.ExerciseType   `json:"type" binding:"required" example:"quiz"`
+	CodeData      *ExerciseCodeData `json:"code_data,omitempty"`
+	QuizData      *ExerciseQuizData `json:"quiz_data,omitempty"`
 }
 
 type ExerciseTranslation struct {
@@ -68,16 +70,18 @@ func ToExercise(d db.Exercise) (Exercise, error) {
 	}
 
 	return Exercise{
-		Uuid:       d.Uuid,
-		CreatedAt:  d.CreatedAt,
-		ModifiedAt: d.ModifiedAt,
-		DeletedAt:  d.DeletedAt,
-		LessonUuid: d.LessonUuid,
-		OrderIndex: d.OrderIndex,
-		Reward:     d.Reward,
-		Type:       d.Type,
-		CodeData:   codeData,
-		QuizData:   quizData,
+		Uuid:          d.Uuid,
+		CreatedAt:     d.CreatedAt,
+		ModifiedAt:    d.ModifiedAt,
+		DeletedAt:     d.DeletedAt,
+		LessonUuid:    d.LessonUuid,
+		OrderIndex:    d.OrderIndex,
+		Reward:        d.Reward,
+		PassThreshold: d.PassThreshold,
+		Deterministic: d.Deterministic,
+		Type:          d.Type,
+		CodeData:      codeData,
+		QuizData:      quizData,
 	}, nil
 }
 
@@ -58,6 +58,7 @@ type UserExercise struct {
 	Submission     json.RawMessage `json:"submission" binding:"required"`
 	Attempts       int32           `json:"attempts" binding:"required" example:"0"`
 	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+	BestScore      int16           `json:"best_score" binding:"required" example:"100"`
 }
 
 type UserExerciseSubmissionCode = fs.Entry
@@ -67,12 +68,33 @@ type UserExerciseSubmissionQuiz struct {
 	Results map[string]bool   `json:"results,omitempty"`
 }
 
+// SubmissionMode tells whether a submission is graded or only executed.
+type SubmissionMode string
+
+const (
+	// SubmissionModeRun executes the code without checkers or progress updates.
+	SubmissionModeRun SubmissionMode = "run"
+	// SubmissionModeSubmit grades the submission and counts it as an attempt.
+	SubmissionModeSubmit SubmissionMode = "submit"
+)
+
 type UserExerciseSubmissionResponse struct {
 	execmodels.ExecuteResponse
-	Passed           bool       `json:"passed" binding:"required" example:"false"`
-	NextLessonUuid   *uuid.UUID `json:"next_lesson_uuid,omitempty"`
-	NextExerciseUuid *uuid.UUID `json:"next_exercise_uuid,omitempty"`
-	Reward           int32      `json:"reward" binding:"required" example:"10"`
+	Mode             SubmissionMode `json:"mode" binding:"required" example:"submit"`
+	Passed           bool           `json:"passed" binding:"required" example:"false"`
+	Score            int16          `json:"score" binding:"required" example:"80"`
+	NextLessonUuid   *uuid.UUID     `json:"next_lesson_uuid,omitempty"`
+	NextExerciseUuid *uuid.UUID     `json:"next_exercise_uuid,omitempty"`
+	Reward           int32          `json:"reward" binding:"required" example:"10"`
+}
+
+// JobEventResponseType tells job events apart from submission responses on the websocket.
+const JobEventResponseType = "job_event"
+
+// JobEventResponse forwards the progress of a job to the client that submitted it.
+type JobEventResponse struct {
+	Type string `json:"type" binding:"required" example:"job_event"`
+	execmodels.JobEvent
 }
 
 func ToUserExerciseStatus(d db.UserExerciseStatus) UserExerciseStatus {
@@ -145,5 +167,6 @@ func ToUserExercise(d db.UserExercise) UserExercise {
 		Submission:     d.Submission,
 		Attempts:       d.Attempts,
 		CompletedAt:    d.CompletedAt,
+		BestScore:      d.BestScore,
 	}
 }
@@ -6,24 +6,28 @@ import (
 )
 
 type CreateExerciseRequest struct {
-	LessonUuid  uuid.UUID       `json:"lesson_uuid" binding:"required"`
-	Type        db.ExerciseType `json:"type" binding:"required" example:"quiz"`
-	OrderIndex  int16           `json:"order_index" binding:"required" example:"1"`
-	Reward      int16           `json:"reward" binding:"required" example:"10"`
-	Language    string          `json:"language" binding:"required" example:"en"`
-	Name        string          `json:"name" binding:"required" example:"Hello World"`
-	Description string          `json:"description" binding:"required" example:"Print Hello World"`
+	LessonUuid    uuid.UUID       `json:"lesson_uuid" binding:"required"`
+	Type          db.ExerciseType `json:"type" binding:"required" example:"quiz"`
+	OrderIndex    int16           `json:"order_index" binding:"required" example:"1"`
+	Reward        int16           `json:"reward" binding:"required" example:"10"`
+	PassThreshold *int16          `json:"pass_threshold" binding:"omitempty,min=0,max=100" example:"100"`
+	Deterministic bool            `json:"deterministic" example:"false"`
+	Language      string          `json:"language" binding:"required" example:"en"`
+	Name          string          `json:"name" binding:"required" example:"Hello World"`
+	Description   string          `json:"description" binding:"required" example:"Print Hello World"`
 }
 
 type UpdateExerciseRequest struct {
-	Uuid        uuid.UUID        `json:"uuid" binding:"required"`
-	Language    string           `json:"language" binding:"required" example:"en"`
-	LessonUuid  *uuid.UUID       `json:"lesson_uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
-	OrderIndex  *int16           `json:"order_index" example:"1"`
-	Reward      *int16           `json:"reward" example:"10"`
-	Type        *db.ExerciseType `json:"type" example:"quiz"`
-	Name        *string          `json:"name" example:"Hello World"`
-	Description *string          `json:"description" example:"Print Hello World"`
+	Uuid          uuid.UUID        `json:"uuid" binding:"required"`
+	Language      string           `json:"language" binding:"required" example:"en"`
+	LessonUuid    *uuid.UUID       `json:"lesson_uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	OrderIndex    *int16           `json:"order_index" example:"1"`
+	Reward        *int16           `json:"reward" example:"10"`
+	PassThreshold *int16           `json:"pass_threshold" binding:"omitempty,min=0,max=100" example:"100"`
+	Deterministic *bool            `json:"deterministic" example:"false"`
+	Type          *db.ExerciseType `json:"type" example:"quiz"`
+	Name          *string          `json:"name" example:"Hello World"`
+	Description   *string          `json:"description" example:"Print Hello World"`
 }
 
 type ListExercisesRequest struct {
@@ -12,6 +12,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// defaultPassThreshold is used when an exercise is created without one: every check has to pass.
+const defaultPassThreshold = 100
+
 type Service struct {
 	q *db.Queries
 	p *pgxpool.Pool
@@ -30,11 +33,18 @@ func (s *Service) Create(ctx context.Context, req CreateExerciseRequest) (models
 
 	qtx := s.q.WithTx(tx)
 
+	passThreshold := int16(defaultPassThreshold)
+	if req.PassThreshold != nil {
+		passThreshold = *req.PassThreshold
+	}
+
 	exercise, err := qtx.CreateExercise(ctx, db.CreateExerciseParams{
-		LessonUuid: req.LessonUuid,
-		OrderIndex: req.OrderIndex,
-		Reward:     req.Reward,
-		Type:       req.Type,
+		LessonUuid:    req.LessonUuid,
+		OrderIndex:    req.OrderIndex,
+		Reward:        req.Reward,
+		Type:          req.Type,
+		PassThreshold: passThreshold,
+		Deterministic: req.Deterministic,
 	})
 
 	if err != nil {
@@ -77,10 +87,12 @@ func (s *Service) Update(ctx context.Context, req UpdateExerciseRequest) (models
 	qtx := s.q.WithTx(tx)
 
 	exercise, err := qtx.UpdateExercise(ctx, db.UpdateExerciseParams{
-		Uuid:       req.Uuid,
-		OrderIndex: req.OrderIndex,
-		Reward:     req.Reward,
-		Type:       req.Type,
+		Uuid:          req.Uuid,
+		OrderIndex:    req.OrderIndex,
+		Reward:        req.Reward,
+		Type:          req.Type,
+		PassThreshold: req.PassThreshold,
+		Deterministic: req.Deterministic,
 	})
 
 	if err != nil {
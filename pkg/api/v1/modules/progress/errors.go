@@ -9,5 +9,7 @@ var (
 	ErrSaveUserExerciseSubmissionFailed  = "Failed to save user exercise submission"
 	ErrValidateSubmissionFailed          = "Failed to validate submission"
 	ErrCompleteUserExerciseFailed        = "Failed to complete user exercise"
+	ErrUpdateBestScoreFailed             = "Failed to update best score"
+	ErrRecordAttemptFailed               = "Failed to record submission attempt"
 	ErrGetExerciseLessonCourseFailed     = "Failed to get exercise lesson course"
 )
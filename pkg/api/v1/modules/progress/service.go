@@ -88,6 +88,34 @@ func (s *Service) CompleteUserExercise(ctx context.Context, userUuid uuid.UUID,
 	return userCourse.NextLessonUuid, userCourse.NextExerciseUuid, nil
 }
 
+// RecordAttempt saves a graded submission and counts it towards the exercise attempts.
+func (s *Service) RecordAttempt(ctx context.Context, userUuid uuid.UUID, exerciseUuid uuid.UUID, submission json.RawMessage) *e.APIError {
+	_, err := s.q.UpdateUserExerciseSubmissionWithAttempts(ctx, db.UpdateUserExerciseSubmissionWithAttemptsParams{
+		UserUuid:     userUuid,
+		ExerciseUuid: exerciseUuid,
+		Submission:   &submission,
+	})
+	if err != nil {
+		return e.NewAPIError(err, ErrRecordAttemptFailed)
+	}
+
+	return nil
+}
+
+// UpdateBestScore records the score of a submission and returns the best score so far.
+func (s *Service) UpdateBestScore(ctx context.Context, userUuid uuid.UUID, exerciseUuid uuid.UUID, score int16) (int16, *e.APIError) {
+	userExercise, err := s.q.UpdateUserExerciseBestScore(ctx, db.UpdateUserExerciseBestScoreParams{
+		UserUuid:     userUuid,
+		ExerciseUuid: exerciseUuid,
+		Score:        score,
+	})
+	if err != nil {
+		return 0, e.NewAPIError(err, ErrUpdateBestScoreFailed)
+	}
+
+	return userExercise.BestScore, nil
+}
+
 func (s *Service) ListUserCoursesWithProgress(ctx context.Context, meUUID uuid.UUID, req ListUserCoursesWithProgressRequest) ([]models.UserCourseWithProgress, *e.APIError) {
 	userCourses, err := s.q.ListUserCoursesWithProgress(ctx, db.ListUserCoursesWithProgressParams{
 		UserUuid: meUUID,
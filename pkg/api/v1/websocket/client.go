@@ -6,10 +6,13 @@ import (
 	"codim/pkg/executors/checkers"
 	d_models "codim/pkg/executors/drivers/models"
 	"codim/pkg/fs"
+	"codim/pkg/rabbitmq"
 	"codim/pkg/utils/logger"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	v "github.com/go-playground/validator/v10"
@@ -22,6 +25,8 @@ const (
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
 	maxMessageSize = 512
+	// publishTimeout bounds waiting for the broker to confirm a job, including a reconnection.
+	publishTimeout = 10 * time.Second
 )
 
 var (
@@ -30,17 +35,62 @@ var (
 )
 
 type Client struct {
-	hub    *Hub
-	conn   *websocket.Conn
+	hub  *Hub
+	conn *websocket.Conn
+	// send is closed by the hub once the client unregistered, sendMu guards it against sends racing the close.
 	send   chan []byte
+	sendMu sync.Mutex
+	closed bool
 	logger *logger.Logger
 	userID uuid.UUID
-	q      *db.Queries
+	// user is the user as authenticated when connecting, it decides the priority of their jobs.
+	user db.User
+	q    *db.Queries
+}
+
+// trySend queues the message for the write pump. It reports false when the client is closed
+// or its buffer is full, the message is dropped then.
+func (c *Client) trySend(message []byte) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops the write pump, later sends are dropped.
+func (c *Client) close() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
 }
 
 type SubmissionMessage struct {
 	ExerciseUuid uuid.UUID   `json:"exercise_uuid" validate:"required"`
 	Submission   interface{} `json:"submission" validate:"required"`
+	// Mode defaults to submit, so older clients keep being graded.
+	Mode models.SubmissionMode `json:"mode" validate:"omitempty,oneof=run submit"`
+}
+
+// cancelMessageType marks a CancelMessage, messages without a type are submissions.
+const cancelMessageType = "cancel"
+
+// CancelMessage asks to cancel a job the client submitted, e.g. before resubmitting a fix.
+type CancelMessage struct {
+	Type  string    `json:"type" validate:"required,eq=cancel"`
+	JobID uuid.UUID `json:"job_id" validate:"required"`
 }
 
 // readPump pumps messages from the websocket connection to the hub.
@@ -66,6 +116,24 @@ func (c *Client) readPump() {
 			break
 		}
 
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(message, &envelope); err == nil && envelope.Type == cancelMessageType {
+			var cancel CancelMessage
+			if err := json.Unmarshal(message, &cancel); err != nil {
+				c.logger.Errorf("error parsing cancel message: %v", err)
+				continue
+			}
+			if err := validate.Struct(cancel); err != nil {
+				c.logger.Errorf("error validating cancel message: %v", err)
+				continue
+			}
+
+			c.hub.cancelJob(c, cancel.JobID)
+			continue
+		}
+
 		// Parse submission
 		var submission SubmissionMessage
 		if err := json.Unmarshal(message, &submission); err != nil {
@@ -78,6 +146,10 @@ func (c *Client) readPump() {
 			continue
 		}
 
+		if submission.Mode == "" {
+			submission.Mode = models.SubmissionModeSubmit
+		}
+
 		row, err := c.q.GetExerciseForSubmission(context.Background(), submission.ExerciseUuid)
 		if err != nil {
 			c.logger.Errorf("error getting exercise subject and type: %v", err)
@@ -107,45 +179,173 @@ func runCodeSubmission(c *Client, submission SubmissionMessage, exercise db.GetE
 		return
 	}
 
+	if submission.Mode == models.SubmissionModeSubmit {
+		if err := c.hub.progressSvc.RecordAttempt(context.Background(), c.userID, submission.ExerciseUuid, submissionBytes); err != nil {
+			c.logger.Errorf("error recording submission attempt: %v", err.OriginalError)
+			return
+		}
+	}
+
 	jobID := uuid.New()
 	queueName := "codexec." + exercise.Subject
 
-	var codeChecker *checkers.CodeChecker
-	var ioChecker *checkers.IOChecker
-	if exercise.CodeChecker != nil {
-		if err := json.Unmarshal(*exercise.CodeChecker, &codeChecker); err != nil {
-			c.logger.Errorf("error unmarshalling code checker: %v", err)
+	req := d_models.ExecutionRequest{
+		JobID:      jobID,
+		Source:     fs.Entry(codeSubmission),
+		EntryPoint: "main." + getExtension(exercise.Subject),
+	}
+
+	if exercise.RunConfig != nil {
+		if err := json.Unmarshal(*exercise.RunConfig, &req.Run); err != nil {
+			c.logger.Errorf("error unmarshalling run config: %v", err)
 			return
 		}
 	}
-	if exercise.IoChecker != nil {
-		if err := json.Unmarshal(*exercise.IoChecker, &ioChecker); err != nil {
-			c.logger.Errorf("error unmarshalling io checker: %v", err)
+
+	if exercise.Environment != nil {
+		req.Environment = *exercise.Environment
+		queueName = d_models.EnvironmentQueue(queueName, req.Environment)
+	}
+
+	if exercise.Database != nil {
+		if err := json.Unmarshal(*exercise.Database, &req.Database); err != nil {
+			c.logger.Errorf("error unmarshalling database: %v", err)
 			return
 		}
 	}
 
-	req := d_models.ExecutionRequest{
-		JobID:       jobID,
-		Source:      fs.Entry(codeSubmission),
-		EntryPoint:  "main." + getExtension(exercise.Subject),
-		CodeChecker: codeChecker,
-		IOChecker:   ioChecker,
+	// Runs only execute the code, the checkers are reserved for graded submissions.
+	if submission.Mode == models.SubmissionModeSubmit {
+		if err := attachCheckers(&req, exercise); err != nil {
+			c.logger.Errorf("error attaching checkers: %v", err)
+			return
+		}
 	}
 
-	c.hub.registerJob <- &JobClient{
-		JobID:        jobID,
-		ExerciseUuid: submission.ExerciseUuid,
-		Client:       c,
+	jobClient := &JobClient{
+		JobID:         jobID,
+		ExerciseUuid:  submission.ExerciseUuid,
+		Client:        c,
+		Mode:          submission.Mode,
+		Reward:        exercise.Reward,
+		PassThreshold: exercise.PassThreshold,
+	}
+
+	// Timings vary from run to run, so only deterministic exercises without a performance checker are cached.
+	if exercise.Deterministic && req.PerformanceChecker == nil {
+		cacheKey, err := c.hub.resultCache.Key(exercise.Subject, req)
+		if err != nil {
+			c.logger.Errorf("error building result cache key: %v", err)
+		} else if res, ok := c.hub.resultCache.GetResult(context.Background(), cacheKey); ok {
+			res.JobID = jobID
+			// The cached timing is the one of the run that filled the cache.
+			res.Timing = nil
+			if err := c.hub.handleResult(context.Background(), jobClient, res); err != nil {
+				c.logger.Errorf("error handling cached result: %v", err)
+			}
+			return
+		} else {
+			jobClient.CacheKey = cacheKey
+		}
 	}
 
-	err = c.hub.producer.PublishObject(context.Background(), "", queueName, req)
+	c.hub.registerJob <- jobClient
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	err = c.hub.jobProducer.PublishObject(ctx, "", queueName, req,
+		c.hub.replies.Expect(jobID.String()),
+		rabbitmq.WithPriority(jobPriority(submission.Mode, c.user)),
+	)
 	if err != nil {
 		c.logger.Errorf("error publishing to rabbitmq: %v", err)
+
+		message := "The submission could not be queued"
+		if errors.Is(err, rabbitmq.ErrUnroutable) {
+			// No worker declared the queue, e.g. none has the exercise's package environment.
+			message = "No worker can run this exercise"
+		}
+
+		// The job never reached a worker, fail it rather than leaving the learner waiting.
+		c.hub.unregisterJobClient(jobID)
+		c.hub.sendResponse(jobClient, models.UserExerciseSubmissionResponse{
+			ExecuteResponse: d_models.FailedResponse(jobID, message),
+			Mode:            submission.Mode,
+		})
+		return
 	}
+
+	c.hub.sendEvent(jobClient, d_models.JobEvent{
+		JobID:     jobID,
+		Status:    d_models.JobStatusQueued,
+		Timestamp: time.Now(),
+	})
+}
+
+// attachCheckers adds the exercise checkers to the execution request.
+func attachCheckers(req *d_models.ExecutionRequest, exercise db.GetExerciseForSubmissionRow) error {
+	if exercise.CodeChecker != nil {
+		if err := json.Unmarshal(*exercise.CodeChecker, &req.CodeChecker); err != nil {
+			return fmt.Errorf("error unmarshalling code checker: %w", err)
+		}
+	}
+	if exercise.IoChecker != nil {
+		if err := json.Unmarshal(*exercise.IoChecker, &req.IOChecker); err != nil {
+			return fmt.Errorf("error unmarshalling io checker: %w", err)
+		}
+	}
+	if exercise.AstChecker != nil {
+		if err := json.Unmarshal(*exercise.AstChecker, &req.ASTChecker); err != nil {
+			return fmt.Errorf("error unmarshalling ast checker: %w", err)
+		}
+	}
+	if exercise.LintChecker != nil {
+		if err := json.Unmarshal(*exercise.LintChecker, &req.LintChecker); err != nil {
+			return fmt.Errorf("error unmarshalling lint checker: %w", err)
+		}
+	}
+	if exercise.PerformanceChecker != nil {
+		if err := json.Unmarshal(*exercise.PerformanceChecker, &req.PerformanceChecker); err != nil {
+			return fmt.Errorf("error unmarshalling performance checker: %w", err)
+		}
+	}
+	if exercise.DifferentialChecker != nil {
+		if err := json.Unmarshal(*exercise.DifferentialChecker, &req.DifferentialChecker); err != nil {
+			return fmt.Errorf("error unmarshalling differential checker: %w", err)
+		}
+	}
+	if exercise.ServerChecker != nil {
+		if err := json.Unmarshal(*exercise.ServerChecker, &req.ServerChecker); err != nil {
+			return fmt.Errorf("error unmarshalling server checker: %w", err)
+		}
+	}
+	if exercise.ResultSetChecker != nil {
+		if err := json.Unmarshal(*exercise.ResultSetChecker, &req.ResultSetChecker); err != nil {
+			return fmt.Errorf("error unmarshalling result set checker: %w", err)
+		}
+	}
+	if exercise.FilesystemChecker != nil {
+		if err := json.Unmarshal(*exercise.FilesystemChecker, &req.FileSystemChecker); err != nil {
+			return fmt.Errorf("error unmarshalling filesystem checker: %w", err)
+		}
+	}
+	if exercise.TypecheckChecker != nil {
+		if err := json.Unmarshal(*exercise.TypecheckChecker, &req.TypeCheckChecker); err != nil {
+			return fmt.Errorf("error unmarshalling typecheck checker: %w", err)
+		}
+	}
+
+	return nil
 }
 
 func runQuizSubmission(c *Client, submission SubmissionMessage, exercise db.GetExerciseForSubmissionRow) {
+	// A quiz has nothing to execute, answers are always graded.
+	if submission.Mode != models.SubmissionModeSubmit {
+		c.logger.Warnf("%s mode is not supported for quiz exercises", submission.Mode)
+		return
+	}
+
 	// Unmarshal the submission into the quiz submission struct
 	submissionBytes, err := json.Marshal(submission.Submission)
 	if err != nil {
@@ -159,6 +359,11 @@ func runQuizSubmission(c *Client, submission SubmissionMessage, exercise db.GetE
 		return
 	}
 
+	if err := c.hub.progressSvc.RecordAttempt(context.Background(), c.userID, submission.ExerciseUuid, submissionBytes); err != nil {
+		c.logger.Errorf("error recording submission attempt: %v", err.OriginalError)
+		return
+	}
+
 	var quizChecker map[string]string
 	if exercise.QuizChecker != nil {
 		if err := json.Unmarshal(*exercise.QuizChecker, &quizChecker); err != nil {
@@ -203,13 +408,19 @@ func runQuizSubmission(c *Client, submission SubmissionMessage, exercise db.GetE
 
 	// Register job client so response can be routed back
 	c.hub.registerJob <- &JobClient{
-		JobID:        jobID,
-		ExerciseUuid: submission.ExerciseUuid,
-		Client:       c,
+		JobID:         jobID,
+		ExerciseUuid:  submission.ExerciseUuid,
+		Client:        c,
+		Mode:          submission.Mode,
+		Reward:        exercise.Reward,
+		PassThreshold: exercise.PassThreshold,
 	}
 
-	// Publish response to rabbitmq
-	err = c.hub.producer.PublishObject(context.Background(), "codexec.results", "", res)
+	// Publish response to this hub's own reply queue
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	err = c.hub.producer.PublishObject(ctx, "", c.hub.replies.Name(), res, rabbitmq.WithCorrelationID(jobID.String()))
 	if err != nil {
 		c.logger.Errorf("error publishing to rabbitmq: %v", err)
 	}
@@ -221,6 +432,12 @@ func getExtension(lang string) string {
 		return "py"
 	case "node", "javascript":
 		return "js"
+	case "sql":
+		return "sql"
+	case "bash":
+		return "sh"
+	case "typescript":
+		return "ts"
 	default:
 		return "txt"
 	}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"codim/pkg/api/v1/cache"
 	"codim/pkg/api/v1/errors"
 	"codim/pkg/api/v1/models"
 	"codim/pkg/api/v1/modules/progress"
@@ -18,12 +19,23 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/jackc/pgx/v5/pgxpool"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
 )
 
+// resultsExchange is where workers broadcast the results of jobs without a reply-to address.
+const resultsExchange = "codexec.results"
+
 type JobClient struct {
 	JobID        uuid.UUID
 	ExerciseUuid uuid.UUID
 	Client       *Client
+	Mode         models.SubmissionMode
+	// Reward is granted in proportion to the best score once the submission passes PassThreshold.
+	Reward        int16
+	PassThreshold int16
+	// CacheKey is set when the result should be cached once it arrives.
+	CacheKey string
 }
 
 type Hub struct {
@@ -35,16 +47,18 @@ type Hub struct {
 	registerJob chan *JobClient
 	rmqClient   *rabbitmq.Client
 	producer    *rabbitmq.Producer
-	consumer    *rabbitmq.Consumer
+	// jobProducer publishes the jobs mandatory, so a job no worker queue takes fails instead of being dropped.
+	jobProducer *rabbitmq.Producer
+	replies     *rabbitmq.ReplyQueue
 	logger      *logger.Logger
 	q           *db.Queries
 	upgrader    websocket.Upgrader
 	progressSvc *progress.Service
+	resultCache *cache.ResultCache
 }
 
-func NewHub(rmqClient *rabbitmq.Client, logger *logger.Logger, q *db.Queries, p *pgxpool.Pool) *Hub {
+func NewHub(rmqClient *rabbitmq.Client, logger *logger.Logger, q *db.Queries, p *pgxpool.Pool, redisClient *redis.Client) *Hub {
 	producer := rmqClient.NewProducer()
-	consumer := rmqClient.NewConsumer()
 	progressSvc := progress.NewService(q, p)
 	return &Hub{
 		register:    make(chan *Client),
@@ -54,10 +68,12 @@ func NewHub(rmqClient *rabbitmq.Client, logger *logger.Logger, q *db.Queries, p
 		registerJob: make(chan *JobClient),
 		rmqClient:   rmqClient,
 		producer:    producer,
-		consumer:    consumer,
+		jobProducer: rmqClient.NewProducer(rabbitmq.WithMandatory()),
+		replies:     rmqClient.NewReplyQueue(resultsExchange),
 		logger:      logger,
 		q:           q,
 		progressSvc: progressSvc,
+		resultCache: cache.NewResultCache(redisClient, logger),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -85,11 +101,22 @@ func (h *Hub) registerClient(client *Client) {
 	h.clients[client] = true
 }
 
+// unregisterClient closes the client and forgets its jobs, their results have nowhere to go.
 func (h *Hub) unregisterClient(client *Client) {
-	if _, ok := h.clients[client]; ok {
-		delete(h.clients, client)
-		close(client.send)
+	if _, ok := h.clients[client]; !ok {
+		return
 	}
+
+	delete(h.clients, client)
+	client.close()
+
+	h.jobMutex.Lock()
+	for jobID, jobClient := range h.jobClients {
+		if jobClient.Client == client {
+			delete(h.jobClients, jobID)
+		}
+	}
+	h.jobMutex.Unlock()
 }
 
 func (h *Hub) registerJobClient(jobClient *JobClient) {
@@ -104,52 +131,83 @@ func (h *Hub) unregisterJobClient(jobID uuid.UUID) {
 	h.jobMutex.Unlock()
 }
 
-func (h *Hub) ListenToRabbitMQ(ctx context.Context, exchangeName string) error {
-	ch, err := h.rmqClient.Connection().Channel()
-	if err != nil {
-		return fmt.Errorf("failed to open channel: %w", err)
-	}
-	defer ch.Close()
-
-	if err := ch.ExchangeDeclare(
-		exchangeName,
-		"fanout",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	); err != nil {
-		return fmt.Errorf("failed to declare exchange: %w", err)
-	}
-
-	// Declare Temporary Queue (Exclusive)
-	q, err := ch.QueueDeclare(
-		"", // name (empty means random)
-		false,
-		false,
-		true,
-		false,
-		nil,
-	)
+// ListenToRabbitMQ consumes the results of the jobs queued by this hub. Workers reply straight to
+// the hub's own queue. It is also bound to the results exchange, which still gets the results of
+// jobs queued without a reply-to address, e.g. by an older API instance during a deploy.
+func (h *Hub) ListenToRabbitMQ(ctx context.Context) error {
+	if err := h.replies.Declare(ctx); err != nil {
+		return err
+	}
+
+	err := h.rmqClient.Declare(ctx, func(ch *amqp.Channel) error {
+		if err := ch.ExchangeDeclare(
+			resultsExchange,
+			"fanout",
+			true,
+			false,
+			false,
+			false,
+			nil,
+		); err != nil {
+			return fmt.Errorf("failed to declare exchange: %w", err)
+		}
+
+		if err := ch.ExchangeDeclare(
+			d_models.CancelExchange,
+			"fanout",
+			true,
+			false,
+			false,
+			false,
+			nil,
+		); err != nil {
+			return fmt.Errorf("failed to declare exchange: %w", err)
+		}
+
+		if err := ch.QueueBind(
+			h.replies.Name(),
+			"",
+			resultsExchange,
+			false,
+			nil,
+		); err != nil {
+			return fmt.Errorf("failed to bind queue: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+		return err
 	}
 
-	if err := ch.QueueBind(
-		q.Name,
-		"",
-		exchangeName,
-		false,
-		nil,
-	); err != nil {
-		return fmt.Errorf("failed to bind queue: %w", err)
+	return h.replies.Listen(ctx, h.messageHandler, 1)
+}
+
+// cancelJob broadcasts the cancellation of a job to the workers. Only the client that submitted the
+// job may cancel it. The job stays registered, the worker answers with a cancelled result.
+func (h *Hub) cancelJob(client *Client, jobID uuid.UUID) {
+	h.jobMutex.Lock()
+	jobClient, ok := h.jobClients[jobID]
+	h.jobMutex.Unlock()
+
+	if !ok || jobClient.Client != client {
+		h.logger.Warnf("ignoring cancellation of unknown job %s", jobID)
+		return
 	}
 
-	return h.consumer.Start(ctx, q.Name, h.messageHandler, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := h.producer.PublishObject(ctx, d_models.CancelExchange, "", d_models.JobCancellation{JobID: jobID}); err != nil {
+		h.logger.Errorf("error publishing cancellation of job %s: %v", jobID, err)
+	}
 }
 
 func (h *Hub) messageHandler(ctx context.Context, body []byte) error {
+	if rabbitmq.MessageType(ctx) == d_models.JobEventMessageType {
+		return h.eventHandler(body)
+	}
+
 	var res d_models.ExecuteResponse
 	if err := json.Unmarshal(body, &res); err != nil {
 		h.logger.Errorf("failed to unmarshal execute response: %v", err)
@@ -163,11 +221,66 @@ func (h *Hub) messageHandler(ctx context.Context, body []byte) error {
 	}
 	h.jobMutex.Unlock()
 
+	// Broadcast results reach every hub, only the one holding the job handles it.
+	if !ok {
+		return nil
+	}
+
+	// A run that hit its time or memory limit may pass on a less loaded worker, so it isn't cached.
+	if jobClient.CacheKey != "" && res.Failure == "" && !res.Cancelled && !res.LimitExceeded {
+		if err := h.resultCache.SetResult(ctx, jobClient.CacheKey, res); err != nil {
+			h.logger.Warnf("failed to cache result of job %s: %v", res.JobID, err)
+		}
+	}
+
+	return h.handleResult(ctx, jobClient, res)
+}
+
+// eventHandler forwards a job event to the client that submitted the job.
+func (h *Hub) eventHandler(body []byte) error {
+	var event d_models.JobEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		h.logger.Errorf("failed to unmarshal job event: %v", err)
+		return rabbitmq.Permanent(err)
+	}
+
+	h.jobMutex.Lock()
+	jobClient, ok := h.jobClients[event.JobID]
+	h.jobMutex.Unlock()
+
+	if ok {
+		h.sendEvent(jobClient, event)
+	}
+
+	return nil
+}
+
+// handleResult grades a result and sends it to the client that submitted the job.
+func (h *Hub) handleResult(ctx context.Context, jobClient *JobClient, res d_models.ExecuteResponse) error {
 	response := models.UserExerciseSubmissionResponse{
 		ExecuteResponse: res,
-		Passed:          res.Passed(),
+		Mode:            jobClient.Mode,
+	}
+
+	// Runs are only echoed back, they neither grade nor touch the learner's progress.
+	// Neither do jobs the worker gave up on or that were cancelled, there is nothing to grade.
+	if jobClient.Mode == models.SubmissionModeRun || res.Failure != "" || res.Cancelled {
+		h.sendResponse(jobClient, response)
+		return nil
 	}
+
+	response.Passed = res.PassedWith(int(jobClient.PassThreshold))
+	response.Score = int16(res.Score())
+
+	bestScore, apiErr := h.progressSvc.UpdateBestScore(ctx, jobClient.Client.userID, jobClient.ExerciseUuid, response.Score)
+	if apiErr != nil {
+		errors.HandleError(nil, h.logger, apiErr, http.StatusInternalServerError)
+		return apiErr.OriginalError
+	}
+
 	if response.Passed {
+		response.Reward = int32(jobClient.Reward) * int32(bestScore) / 100
+
 		nextLessonUuid, nextExerciseUuid, err := h.progressSvc.CompleteUserExercise(ctx, jobClient.Client.userID, jobClient.ExerciseUuid)
 		if err != nil {
 			errors.HandleError(nil, h.logger, err, http.StatusInternalServerError)
@@ -178,21 +291,32 @@ func (h *Hub) messageHandler(ctx context.Context, body []byte) error {
 		response.NextExerciseUuid = nextExerciseUuid
 	}
 
-	responseBytes, err := json.Marshal(response)
+	h.sendResponse(jobClient, response)
+
+	return nil
+}
+
+func (h *Hub) sendResponse(jobClient *JobClient, response models.UserExerciseSubmissionResponse) {
+	h.send(jobClient, response)
+}
+
+func (h *Hub) sendEvent(jobClient *JobClient, event d_models.JobEvent) {
+	h.send(jobClient, models.JobEventResponse{
+		Type:     models.JobEventResponseType,
+		JobEvent: event,
+	})
+}
+
+func (h *Hub) send(jobClient *JobClient, message any) {
+	responseBytes, err := json.Marshal(message)
 	if err != nil {
 		errors.HandleError(nil, h.logger, errors.NewAPIError(err, "Internal server error"), http.StatusInternalServerError)
-		return err
+		return
 	}
 
-	if ok {
-		select {
-		case jobClient.Client.send <- responseBytes:
-		default:
-			// Client buffer full or closed
-		}
+	if !jobClient.Client.trySend(responseBytes) {
+		h.logger.Warnf("dropping message for job %s, the client is gone or not keeping up", jobClient.JobID)
 	}
-
-	return nil
 }
 
 func (h *Hub) ServeWs(c *gin.Context) {
@@ -217,6 +341,10 @@ func (h *Hub) ServeWs(c *gin.Context) {
 		return
 	}
 
+	// Without the user object the jobs still run, at the lowest priority.
+	user, _ := c.Get("user")
+	dbUser, _ := user.(db.User)
+
 	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		h.logger.Errorf("error upgrading websocket: %v", err)
@@ -230,6 +358,7 @@ func (h *Hub) ServeWs(c *gin.Context) {
 		send:   make(chan []byte, 256),
 		logger: h.logger,
 		userID: userID,
+		user:   dbUser,
 		q:      h.q,
 	}
 	h.register <- client
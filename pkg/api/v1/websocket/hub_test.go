@@ -0,0 +1,38 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnregisterClient(t *testing.T) {
+	h := &Hub{
+		clients:    make(map[*Client]bool),
+		jobClients: make(map[uuid.UUID]*JobClient),
+	}
+
+	gone := &Client{hub: h, send: make(chan []byte, 1)}
+	other := &Client{hub: h, send: make(chan []byte, 1)}
+	h.registerClient(gone)
+	h.registerClient(other)
+
+	goneJob := &JobClient{JobID: uuid.New(), Client: gone}
+	otherJob := &JobClient{JobID: uuid.New(), Client: other}
+	h.registerJobClient(goneJob)
+	h.registerJobClient(otherJob)
+
+	h.unregisterClient(gone)
+	// Unregistering twice, e.g. from both pumps, doesn't close the channel again.
+	h.unregisterClient(gone)
+
+	require.NotContains(t, h.jobClients, goneJob.JobID)
+	require.Contains(t, h.jobClients, otherJob.JobID)
+
+	// A result racing the disconnect is dropped instead of panicking on the closed channel.
+	require.False(t, gone.trySend([]byte("result")))
+	require.True(t, other.trySend([]byte("result")))
+	// A full buffer drops the message too.
+	require.False(t, other.trySend([]byte("event")))
+}
@@ -0,0 +1,26 @@
+package websocket
+
+import (
+	"codim/pkg/api/v1/models"
+	"codim/pkg/db"
+	d_models "codim/pkg/executors/drivers/models"
+)
+
+// jobPriority ranks a job in its queue, from 0 to d_models.MaxJobPriority. Graded submissions rank
+// above free runs, which the learner can simply repeat. Admins, i.e. staff and instructors, rank
+// above learners, and learners who haven't verified their account rank lowest.
+func jobPriority(mode models.SubmissionMode, user db.User) uint8 {
+	var priority uint8
+	if mode == models.SubmissionModeSubmit {
+		priority += 2
+	}
+
+	switch {
+	case user.IsAdmin:
+		priority += 2
+	case user.IsVerified:
+		priority++
+	}
+
+	return min(priority, d_models.MaxJobPriority)
+}
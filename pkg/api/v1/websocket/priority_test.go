@@ -0,0 +1,25 @@
+package websocket
+
+import (
+	"codim/pkg/api/v1/models"
+	"codim/pkg/db"
+	d_models "codim/pkg/executors/drivers/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobPriority(t *testing.T) {
+	unverified := db.User{}
+	verified := db.User{IsVerified: true}
+	admin := db.User{IsAdmin: true, IsVerified: true}
+
+	require.Equal(t, uint8(0), jobPriority(models.SubmissionModeRun, unverified))
+	require.Equal(t, uint8(1), jobPriority(models.SubmissionModeRun, verified))
+	require.Equal(t, uint8(2), jobPriority(models.SubmissionModeSubmit, unverified))
+	require.Equal(t, uint8(3), jobPriority(models.SubmissionModeSubmit, verified))
+	require.Equal(t, uint8(d_models.MaxJobPriority), jobPriority(models.SubmissionModeSubmit, admin))
+
+	// A learner's graded submission still ranks above an admin's free run.
+	require.Greater(t, jobPriority(models.SubmissionModeSubmit, verified), jobPriority(models.SubmissionModeRun, admin))
+}
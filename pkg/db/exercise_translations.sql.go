@@ -71,7 +71,7 @@ func (q *Queries) DeleteExerciseTranslation(ctx context.Context, argUuid uuid.UU
 }
 
 const getExerciseTranslation = `-- name: GetExerciseTranslation :one
-SELECT exercise_translations.uuid, exercise_uuid, language, name, description, exercise_translations.code_data, exercise_translations.quiz_data, exercises.uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, exercises.code_data, exercises.quiz_data, quiz_checker, io_checker, code_checker FROM "exercise_translations"
+SELECT exercise_translations.uuid, exercise_uuid, language, name, description, exercise_translations.code_data, exercise_translations.quiz_data, exercises.uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, exercises.code_data, exercises.quiz_data, quiz_checker, io_checker, code_checker, ast_checker, lint_checker, performance_checker, differential_checker, pass_threshold, deterministic, run_config, environment, server_checker, database, result_set_checker, filesystem_checker, typecheck_checker FROM "exercise_translations"
 JOIN "exercises" ON "exercise_translations"."exercise_uuid" = "exercises"."uuid"
 WHERE "exercise_translations"."uuid" = $1
 AND "exercises"."deleted_at" IS NULL
@@ -79,26 +79,39 @@ LIMIT 1
 `
 
 type GetExerciseTranslationRow struct {
-	Uuid         uuid.UUID        `json:"uuid"`
-	ExerciseUuid uuid.UUID        `json:"exercise_uuid"`
-	Language     string           `json:"language"`
-	Name         string           `json:"name"`
-	Description  string           `json:"description"`
-	CodeData     *json.RawMessage `json:"code_data"`
-	QuizData     *json.RawMessage `json:"quiz_data"`
-	Uuid_2       uuid.UUID        `json:"uuid_2"`
-	CreatedAt    time.Time        `json:"created_at"`
-	ModifiedAt   time.Time        `json:"modified_at"`
-	DeletedAt    *time.Time       `json:"deleted_at"`
-	LessonUuid   uuid.UUID        `json:"lesson_uuid"`
-	OrderIndex   int16            `json:"order_index"`
-	Reward       int16            `json:"reward"`
-	Type         ExerciseType     `json:"type"`
-	CodeData_2   *json.RawMessage `json:"code_data_2"`
-	QuizData_2   *json.RawMessage `json:"quiz_data_2"`
-	QuizChecker  *json.RawMessage `json:"quiz_checker"`
-	IoChecker    *json.RawMessage `json:"io_checker"`
-	CodeChecker  *json.RawMessage `json:"code_checker"`
+	Uuid                uuid.UUID        `json:"uuid"`
+	ExerciseUuid        uuid.UUID        `json:"exercise_uuid"`
+	Language            string           `json:"language"`
+	Name                string           `json:"name"`
+	Description         string           `json:"description"`
+	CodeData            *json.RawMessage `json:"code_data"`
+	QuizData            *json.RawMessage `json:"quiz_data"`
+	Uuid_2              uuid.UUID        `json:"uuid_2"`
+	CreatedAt           time.Time        `json:"created_at"`
+	ModifiedAt          time.Time        `json:"modified_at"`
+	DeletedAt           *time.Time       `json:"deleted_at"`
+	LessonUuid          uuid.UUID        `json:"lesson_uuid"`
+	OrderIndex          int16            `json:"order_index"`
+	Reward              int16            `json:"reward"`
+	Type                ExerciseType     `json:"type"`
+	CodeData_2          *json.RawMessage `json:"code_data_2"`
+	QuizData_2          *json.RawMessage `json:"quiz_data_2"`
+	QuizChecker         *json.RawMessage `json:"quiz_checker"`
+	IoChecker           *json.RawMessage `json:"io_checker"`
+	CodeChecker         *json.RawMessage `json:"code_checker"`
+	AstChecker          *json.RawMessage `json:"ast_checker"`
+	LintChecker         *json.RawMessage `json:"lint_checker"`
+	PerformanceChecker  *json.RawMessage `json:"performance_checker"`
+	DifferentialChecker *json.RawMessage `json:"differential_checker"`
+	PassThreshold       int16            `json:"pass_threshold"`
+	Deterministic       bool             `json:"deterministic"`
+	RunConfig           *json.RawMessage `json:"run_config"`
+	Environment         *string          `json:"environment"`
+	ServerChecker       *json.RawMessage `json:"server_checker"`
+	Database            *json.RawMessage `json:"database"`
+	ResultSetChecker    *json.RawMessage `json:"result_set_checker"`
+	FilesystemChecker   *json.RawMessage `json:"filesystem_checker"`
+	TypecheckChecker    *json.RawMessage `json:"typecheck_checker"`
 }
 
 func (q *Queries) GetExerciseTranslation(ctx context.Context, argUuid uuid.UUID) (GetExerciseTranslationRow, error) {
@@ -125,6 +138,19 @@ func (q *Queries) GetExerciseTranslation(ctx context.Context, argUuid uuid.UUID)
 		&i.QuizChecker,
 		&i.IoChecker,
 		&i.CodeChecker,
+		&i.AstChecker,
+		&i.LintChecker,
+		&i.PerformanceChecker,
+		&i.DifferentialChecker,
+		&i.PassThreshold,
+		&i.Deterministic,
+		&i.RunConfig,
+		&i.Environment,
+		&i.ServerChecker,
+		&i.Database,
+		&i.ResultSetChecker,
+		&i.FilesystemChecker,
+		&i.TypecheckChecker,
 	)
 	return i, err
 }
@@ -8,16 +8,18 @@ type ExerciseWithTranslation struct {
 func (g *GetExerciseRow) ToExerciseWithTranslation() ExerciseWithTranslation {
 	return ExerciseWithTranslation{
 		Exercise: Exercise{
-			Uuid:       g.Uuid,
-			CreatedAt:  g.CreatedAt,
-			ModifiedAt: g.ModifiedAt,
-			DeletedAt:  g.DeletedAt,
-			LessonUuid: g.LessonUuid,
-			OrderIndex: g.OrderIndex,
-			Reward:     g.Reward,
-			Type:       g.Type,
-			CodeData:   g.CodeData,
-			QuizData:   g.QuizData,
+			Uuid:          g.Uuid,
+			CreatedAt:     g.CreatedAt,
+			ModifiedAt:    g.ModifiedAt,
+			DeletedAt:     g.DeletedAt,
+			LessonUuid:    g.LessonUuid,
+			OrderIndex:    g.OrderIndex,
+			Reward:        g.Reward,
+			PassThreshold: g.PassThreshold,
+			Deterministic: g.Deterministic,
+			Type:          g.Type,
+			CodeData:      g.CodeData,
+			QuizData:      g.QuizData,
 		},
 		Translation: ExerciseTranslation{
 			Uuid:         g.Uuid_2,
@@ -34,16 +36,18 @@ func (g *GetExerciseRow) ToExerciseWithTranslation() ExerciseWithTranslation {
 func (l *ListExercisesRow) ToExerciseWithTranslation() ExerciseWithTranslation {
 	return ExerciseWithTranslation{
 		Exercise: Exercise{
-			Uuid:       l.Uuid,
-			CreatedAt:  l.CreatedAt,
-			ModifiedAt: l.ModifiedAt,
-			DeletedAt:  l.DeletedAt,
-			LessonUuid: l.LessonUuid,
-			OrderIndex: l.OrderIndex,
-			Reward:     l.Reward,
-			Type:       l.Type,
-			CodeData:   l.CodeData,
-			QuizData:   l.QuizData,
+			Uuid:          l.Uuid,
+			CreatedAt:     l.CreatedAt,
+			ModifiedAt:    l.ModifiedAt,
+			DeletedAt:     l.DeletedAt,
+			LessonUuid:    l.LessonUuid,
+			OrderIndex:    l.OrderIndex,
+			Reward:        l.Reward,
+			PassThreshold: l.PassThreshold,
+			Deterministic: l.Deterministic,
+			Type:          l.Type,
+			CodeData:      l.CodeData,
+			QuizData:      l.QuizData,
 		},
 		Translation: ExerciseTranslation{
 			Uuid:         l.Uuid_2,
@@ -35,23 +35,49 @@ INSERT INTO "exercises" (
   "quiz_data",
   "quiz_checker",
   "io_checker",
-  "code_checker"
+  "code_checker",
+  "ast_checker",
+  "lint_checker",
+  "performance_checker",
+  "differential_checker",
+  "pass_threshold",
+  "deterministic",
+  "run_config",
+  "environment",
+  "server_checker",
+  "database",
+  "result_set_checker",
+  "filesystem_checker",
+  "typecheck_checker"
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
 )
-RETURNING uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, code_data, quiz_data, quiz_checker, io_checker, code_checker
+RETURNING uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, code_data, quiz_data, quiz_checker, io_checker, code_checker, ast_checker, lint_checker, performance_checker, differential_checker, pass_threshold, deterministic, run_config, environment, server_checker, database, result_set_checker, filesystem_checker, typecheck_checker
 `
 
 type CreateExerciseParams struct {
-	LessonUuid  uuid.UUID        `json:"lesson_uuid"`
-	OrderIndex  int16            `json:"order_index"`
-	Reward      int16            `json:"reward"`
-	Type        ExerciseType     `json:"type"`
-	CodeData    *json.RawMessage `json:"code_data"`
-	QuizData    *json.RawMessage `json:"quiz_data"`
-	QuizChecker *json.RawMessage `json:"quiz_checker"`
-	IoChecker   *json.RawMessage `json:"io_checker"`
-	CodeChecker *json.RawMessage `json:"code_checker"`
+	LessonUuid          uuid.UUID        `json:"lesson_uuid"`
+	OrderIndex          int16            `json:"order_index"`
+	Reward              int16            `json:"reward"`
+	Type                ExerciseType     `json:"type"`
+	CodeData            *json.RawMessage `json:"code_data"`
+	QuizData            *json.RawMessage `json:"quiz_data"`
+	QuizChecker         *json.RawMessage `json:"quiz_checker"`
+	IoChecker           *json.RawMessage `json:"io_checker"`
+	CodeChecker         *json.RawMessage `json:"code_checker"`
+	AstChecker          *json.RawMessage `json:"ast_checker"`
+	LintChecker         *json.RawMessage `json:"lint_checker"`
+	PerformanceChecker  *json.RawMessage `json:"performance_checker"`
+	DifferentialChecker *json.RawMessage `json:"differential_checker"`
+	PassThreshold       int16            `json:"pass_threshold"`
+	Deterministic       bool             `json:"deterministic"`
+	RunConfig           *json.RawMessage `json:"run_config"`
+	Environment         *string          `json:"environment"`
+	ServerChecker       *json.RawMessage `json:"server_checker"`
+	Database            *json.RawMessage `json:"database"`
+	ResultSetChecker    *json.RawMessage `json:"result_set_checker"`
+	FilesystemChecker   *json.RawMessage `json:"filesystem_checker"`
+	TypecheckChecker    *json.RawMessage `json:"typecheck_checker"`
 }
 
 func (q *Queries) CreateExercise(ctx context.Context, arg CreateExerciseParams) (Exercise, error) {
@@ -65,6 +91,19 @@ func (q *Queries) CreateExercise(ctx context.Context, arg CreateExerciseParams)
 		arg.QuizChecker,
 		arg.IoChecker,
 		arg.CodeChecker,
+		arg.AstChecker,
+		arg.LintChecker,
+		arg.PerformanceChecker,
+		arg.DifferentialChecker,
+		arg.PassThreshold,
+		arg.Deterministic,
+		arg.RunConfig,
+		arg.Environment,
+		arg.ServerChecker,
+		arg.Database,
+		arg.ResultSetChecker,
+		arg.FilesystemChecker,
+		arg.TypecheckChecker,
 	)
 	var i Exercise
 	err := row.Scan(
@@ -81,6 +120,19 @@ func (q *Queries) CreateExercise(ctx context.Context, arg CreateExerciseParams)
 		&i.QuizChecker,
 		&i.IoChecker,
 		&i.CodeChecker,
+		&i.AstChecker,
+		&i.LintChecker,
+		&i.PerformanceChecker,
+		&i.DifferentialChecker,
+		&i.PassThreshold,
+		&i.Deterministic,
+		&i.RunConfig,
+		&i.Environment,
+		&i.ServerChecker,
+		&i.Database,
+		&i.ResultSetChecker,
+		&i.FilesystemChecker,
+		&i.TypecheckChecker,
 	)
 	return i, err
 }
@@ -97,7 +149,7 @@ func (q *Queries) DeleteExercise(ctx context.Context, argUuid uuid.UUID) error {
 }
 
 const getExercise = `-- name: GetExercise :one
-SELECT exercises.uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, exercises.code_data, exercises.quiz_data, quiz_checker, io_checker, code_checker, exercise_translations.uuid, exercise_uuid, language, name, description, exercise_translations.code_data, exercise_translations.quiz_data FROM "exercises"
+SELECT exercises.uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, exercises.code_data, exercises.quiz_data, quiz_checker, io_checker, code_checker, ast_checker, lint_checker, performance_checker, differential_checker, pass_threshold, deterministic, run_config, environment, server_checker, database, result_set_checker, filesystem_checker, typecheck_checker, exercise_translations.uuid, exercise_uuid, language, name, description, exercise_translations.code_data, exercise_translations.quiz_data FROM "exercises"
 JOIN "exercise_translations" ON "exercises"."uuid" = "exercise_translations"."exercise_uuid" AND "exercise_translations"."language" = $2
 WHERE "exercises"."uuid" = $1 AND "exercises"."deleted_at" IS NULL 
 LIMIT 1
@@ -109,26 +161,39 @@ type GetExerciseParams struct {
 }
 
 type GetExerciseRow struct {
-	Uuid         uuid.UUID        `json:"uuid"`
-	CreatedAt    time.Time        `json:"created_at"`
-	ModifiedAt   time.Time        `json:"modified_at"`
-	DeletedAt    *time.Time       `json:"deleted_at"`
-	LessonUuid   uuid.UUID        `json:"lesson_uuid"`
-	OrderIndex   int16            `json:"order_index"`
-	Reward       int16            `json:"reward"`
-	Type         ExerciseType     `json:"type"`
-	CodeData     *json.RawMessage `json:"code_data"`
-	QuizData     *json.RawMessage `json:"quiz_data"`
-	QuizChecker  *json.RawMessage `json:"quiz_checker"`
-	IoChecker    *json.RawMessage `json:"io_checker"`
-	CodeChecker  *json.RawMessage `json:"code_checker"`
-	Uuid_2       uuid.UUID        `json:"uuid_2"`
-	ExerciseUuid uuid.UUID        `json:"exercise_uuid"`
-	Language     string           `json:"language"`
-	Name         string           `json:"name"`
-	Description  string           `json:"description"`
-	CodeData_2   *json.RawMessage `json:"code_data_2"`
-	QuizData_2   *json.RawMessage `json:"quiz_data_2"`
+	Uuid                uuid.UUID        `json:"uuid"`
+	CreatedAt           time.Time        `json:"created_at"`
+	ModifiedAt          time.Time        `json:"modified_at"`
+	DeletedAt           *time.Time       `json:"deleted_at"`
+	LessonUuid          uuid.UUID        `json:"lesson_uuid"`
+	OrderIndex          int16            `json:"order_index"`
+	Reward              int16            `json:"reward"`
+	Type                ExerciseType     `json:"type"`
+	CodeData            *json.RawMessage `json:"code_data"`
+	QuizData            *json.RawMessage `json:"quiz_data"`
+	QuizChecker         *json.RawMessage `json:"quiz_checker"`
+	IoChecker           *json.RawMessage `json:"io_checker"`
+	CodeChecker         *json.RawMessage `json:"code_checker"`
+	AstChecker          *json.RawMessage `json:"ast_checker"`
+	LintChecker         *json.RawMessage `json:"lint_checker"`
+	PerformanceChecker  *json.RawMessage `json:"performance_checker"`
+	DifferentialChecker *json.RawMessage `json:"differential_checker"`
+	PassThreshold       int16            `json:"pass_threshold"`
+	Deterministic       bool             `json:"deterministic"`
+	RunConfig           *json.RawMessage `json:"run_config"`
+	Environment         *string          `json:"environment"`
+	ServerChecker       *json.RawMessage `json:"server_checker"`
+	Database            *json.RawMessage `json:"database"`
+	ResultSetChecker    *json.RawMessage `json:"result_set_checker"`
+	FilesystemChecker   *json.RawMessage `json:"filesystem_checker"`
+	TypecheckChecker    *json.RawMessage `json:"typecheck_checker"`
+	Uuid_2              uuid.UUID        `json:"uuid_2"`
+	ExerciseUuid        uuid.UUID        `json:"exercise_uuid"`
+	Language            string           `json:"language"`
+	Name                string           `json:"name"`
+	Description         string           `json:"description"`
+	CodeData_2          *json.RawMessage `json:"code_data_2"`
+	QuizData_2          *json.RawMessage `json:"quiz_data_2"`
 }
 
 func (q *Queries) GetExercise(ctx context.Context, arg GetExerciseParams) (GetExerciseRow, error) {
@@ -148,6 +213,19 @@ func (q *Queries) GetExercise(ctx context.Context, arg GetExerciseParams) (GetEx
 		&i.QuizChecker,
 		&i.IoChecker,
 		&i.CodeChecker,
+		&i.AstChecker,
+		&i.LintChecker,
+		&i.PerformanceChecker,
+		&i.DifferentialChecker,
+		&i.PassThreshold,
+		&i.Deterministic,
+		&i.RunConfig,
+		&i.Environment,
+		&i.ServerChecker,
+		&i.Database,
+		&i.ResultSetChecker,
+		&i.FilesystemChecker,
+		&i.TypecheckChecker,
 		&i.Uuid_2,
 		&i.ExerciseUuid,
 		&i.Language,
@@ -160,7 +238,7 @@ func (q *Queries) GetExercise(ctx context.Context, arg GetExerciseParams) (GetEx
 }
 
 const getExerciseForSubmission = `-- name: GetExerciseForSubmission :one
-SELECT "courses"."subject", "exercises"."type", "exercises"."code_checker", "exercises"."io_checker", "exercises"."quiz_checker" FROM "courses"
+SELECT "courses"."subject", "exercises"."type", "exercises"."code_checker", "exercises"."io_checker", "exercises"."quiz_checker", "exercises"."ast_checker", "exercises"."lint_checker", "exercises"."performance_checker", "exercises"."differential_checker", "exercises"."reward", "exercises"."pass_threshold", "exercises"."deterministic", "exercises"."run_config", "exercises"."environment", "exercises"."server_checker", "exercises"."database", "exercises"."result_set_checker", "exercises"."filesystem_checker", "exercises"."typecheck_checker" FROM "courses"
 JOIN "lessons" ON "courses"."uuid" = "lessons"."course_uuid"
 JOIN "exercises" ON "lessons"."uuid" = "exercises"."lesson_uuid"
 WHERE "exercises"."uuid" = $1
@@ -168,11 +246,25 @@ LIMIT 1
 `
 
 type GetExerciseForSubmissionRow struct {
-	Subject     string           `json:"subject"`
-	Type        ExerciseType     `json:"type"`
-	CodeChecker *json.RawMessage `json:"code_checker"`
-	IoChecker   *json.RawMessage `json:"io_checker"`
-	QuizChecker *json.RawMessage `json:"quiz_checker"`
+	Subject             string           `json:"subject"`
+	Type                ExerciseType     `json:"type"`
+	CodeChecker         *json.RawMessage `json:"code_checker"`
+	IoChecker           *json.RawMessage `json:"io_checker"`
+	QuizChecker         *json.RawMessage `json:"quiz_checker"`
+	AstChecker          *json.RawMessage `json:"ast_checker"`
+	LintChecker         *json.RawMessage `json:"lint_checker"`
+	PerformanceChecker  *json.RawMessage `json:"performance_checker"`
+	DifferentialChecker *json.RawMessage `json:"differential_checker"`
+	Reward              int16            `json:"reward"`
+	PassThreshold       int16            `json:"pass_threshold"`
+	Deterministic       bool             `json:"deterministic"`
+	RunConfig           *json.RawMessage `json:"run_config"`
+	Environment         *string          `json:"environment"`
+	ServerChecker       *json.RawMessage `json:"server_checker"`
+	Database            *json.RawMessage `json:"database"`
+	ResultSetChecker    *json.RawMessage `json:"result_set_checker"`
+	FilesystemChecker   *json.RawMessage `json:"filesystem_checker"`
+	TypecheckChecker    *json.RawMessage `json:"typecheck_checker"`
 }
 
 func (q *Queries) GetExerciseForSubmission(ctx context.Context, argUuid uuid.UUID) (GetExerciseForSubmissionRow, error) {
@@ -184,6 +276,20 @@ func (q *Queries) GetExerciseForSubmission(ctx context.Context, argUuid uuid.UUI
 		&i.CodeChecker,
 		&i.IoChecker,
 		&i.QuizChecker,
+		&i.AstChecker,
+		&i.LintChecker,
+		&i.PerformanceChecker,
+		&i.DifferentialChecker,
+		&i.Reward,
+		&i.PassThreshold,
+		&i.Deterministic,
+		&i.RunConfig,
+		&i.Environment,
+		&i.ServerChecker,
+		&i.Database,
+		&i.ResultSetChecker,
+		&i.FilesystemChecker,
+		&i.TypecheckChecker,
 	)
 	return i, err
 }
@@ -250,7 +356,7 @@ func (q *Queries) HardDeleteExercise(ctx context.Context, argUuid uuid.UUID) err
 }
 
 const listExercises = `-- name: ListExercises :many
-SELECT exercises.uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, exercises.code_data, exercises.quiz_data, quiz_checker, io_checker, code_checker, exercise_translations.uuid, exercise_uuid, language, name, description, exercise_translations.code_data, exercise_translations.quiz_data FROM "exercises"
+SELECT exercises.uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, exercises.code_data, exercises.quiz_data, quiz_checker, io_checker, code_checker, ast_checker, lint_checker, performance_checker, differential_checker, pass_threshold, deterministic, run_config, environment, server_checker, database, result_set_checker, filesystem_checker, typecheck_checker, exercise_translations.uuid, exercise_uuid, language, name, description, exercise_translations.code_data, exercise_translations.quiz_data FROM "exercises"
 JOIN "exercise_translations" ON "exercises"."uuid" = "exercise_translations"."exercise_uuid" AND "exercise_translations"."language" = $3
 WHERE "exercises"."deleted_at" IS NULL
 AND   ($4::uuid IS NULL OR "lesson_uuid" = $4)
@@ -266,26 +372,39 @@ type ListExercisesParams struct {
 }
 
 type ListExercisesRow struct {
-	Uuid         uuid.UUID        `json:"uuid"`
-	CreatedAt    time.Time        `json:"created_at"`
-	ModifiedAt   time.Time        `json:"modified_at"`
-	DeletedAt    *time.Time       `json:"deleted_at"`
-	LessonUuid   uuid.UUID        `json:"lesson_uuid"`
-	OrderIndex   int16            `json:"order_index"`
-	Reward       int16            `json:"reward"`
-	Type         ExerciseType     `json:"type"`
-	CodeData     *json.RawMessage `json:"code_data"`
-	QuizData     *json.RawMessage `json:"quiz_data"`
-	QuizChecker  *json.RawMessage `json:"quiz_checker"`
-	IoChecker    *json.RawMessage `json:"io_checker"`
-	CodeChecker  *json.RawMessage `json:"code_checker"`
-	Uuid_2       uuid.UUID        `json:"uuid_2"`
-	ExerciseUuid uuid.UUID        `json:"exercise_uuid"`
-	Language     string           `json:"language"`
-	Name         string           `json:"name"`
-	Description  string           `json:"description"`
-	CodeData_2   *json.RawMessage `json:"code_data_2"`
-	QuizData_2   *json.RawMessage `json:"quiz_data_2"`
+	Uuid                uuid.UUID        `json:"uuid"`
+	CreatedAt           time.Time        `json:"created_at"`
+	ModifiedAt          time.Time        `json:"modified_at"`
+	DeletedAt           *time.Time       `json:"deleted_at"`
+	LessonUuid          uuid.UUID        `json:"lesson_uuid"`
+	OrderIndex          int16            `json:"order_index"`
+	Reward              int16            `json:"reward"`
+	Type                ExerciseType     `json:"type"`
+	CodeData            *json.RawMessage `json:"code_data"`
+	QuizData            *json.RawMessage `json:"quiz_data"`
+	QuizChecker         *json.RawMessage `json:"quiz_checker"`
+	IoChecker           *json.RawMessage `json:"io_checker"`
+	CodeChecker         *json.RawMessage `json:"code_checker"`
+	AstChecker          *json.RawMessage `json:"ast_checker"`
+	LintChecker         *json.RawMessage `json:"lint_checker"`
+	PerformanceChecker  *json.RawMessage `json:"performance_checker"`
+	DifferentialChecker *json.RawMessage `json:"differential_checker"`
+	PassThreshold       int16            `json:"pass_threshold"`
+	Deterministic       bool             `json:"deterministic"`
+	RunConfig           *json.RawMessage `json:"run_config"`
+	Environment         *string          `json:"environment"`
+	ServerChecker       *json.RawMessage `json:"server_checker"`
+	Database            *json.RawMessage `json:"database"`
+	ResultSetChecker    *json.RawMessage `json:"result_set_checker"`
+	FilesystemChecker   *json.RawMessage `json:"filesystem_checker"`
+	TypecheckChecker    *json.RawMessage `json:"typecheck_checker"`
+	Uuid_2              uuid.UUID        `json:"uuid_2"`
+	ExerciseUuid        uuid.UUID        `json:"exercise_uuid"`
+	Language            string           `json:"language"`
+	Name                string           `json:"name"`
+	Description         string           `json:"description"`
+	CodeData_2          *json.RawMessage `json:"code_data_2"`
+	QuizData_2          *json.RawMessage `json:"quiz_data_2"`
 }
 
 func (q *Queries) ListExercises(ctx context.Context, arg ListExercisesParams) ([]ListExercisesRow, error) {
@@ -316,6 +435,19 @@ func (q *Queries) ListExercises(ctx context.Context, arg ListExercisesParams) ([
 			&i.QuizChecker,
 			&i.IoChecker,
 			&i.CodeChecker,
+			&i.AstChecker,
+			&i.LintChecker,
+			&i.PerformanceChecker,
+			&i.DifferentialChecker,
+			&i.PassThreshold,
+			&i.Deterministic,
+			&i.RunConfig,
+			&i.Environment,
+			&i.ServerChecker,
+			&i.Database,
+			&i.ResultSetChecker,
+			&i.FilesystemChecker,
+			&i.TypecheckChecker,
 			&i.Uuid_2,
 			&i.ExerciseUuid,
 			&i.Language,
@@ -355,21 +487,47 @@ SET "order_index" = COALESCE($2, "order_index"),
     "quiz_checker" = COALESCE($7, "quiz_checker"),
     "io_checker" = COALESCE($8, "io_checker"),
     "code_checker" = COALESCE($9, "code_checker"),
+    "ast_checker" = COALESCE($10, "ast_checker"),
+    "lint_checker" = COALESCE($11, "lint_checker"),
+    "performance_checker" = COALESCE($12, "performance_checker"),
+    "differential_checker" = COALESCE($13, "differential_checker"),
+    "pass_threshold" = COALESCE($14, "pass_threshold"),
+    "deterministic" = COALESCE($15, "deterministic"),
+    "run_config" = COALESCE($16, "run_config"),
+    "environment" = COALESCE($17, "environment"),
+    "server_checker" = COALESCE($18, "server_checker"),
+    "database" = COALESCE($19, "database"),
+    "result_set_checker" = COALESCE($20, "result_set_checker"),
+    "filesystem_checker" = COALESCE($21, "filesystem_checker"),
+    "typecheck_checker" = COALESCE($22, "typecheck_checker"),
     "modified_at" = NOW()
 WHERE "uuid" = $1
-RETURNING uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, code_data, quiz_data, quiz_checker, io_checker, code_checker
+RETURNING uuid, created_at, modified_at, deleted_at, lesson_uuid, order_index, reward, type, code_data, quiz_data, quiz_checker, io_checker, code_checker, ast_checker, lint_checker, performance_checker, differential_checker, pass_threshold, deterministic, run_config, environment, server_checker, database, result_set_checker, filesystem_checker, typecheck_checker
 `
 
 type UpdateExerciseParams struct {
-	Uuid        uuid.UUID        `json:"uuid"`
-	OrderIndex  *int16           `json:"order_index"`
-	Reward      *int16           `json:"reward"`
-	Type        *ExerciseType    `json:"type"`
-	CodeData    *json.RawMessage `json:"code_data"`
-	QuizData    *json.RawMessage `json:"quiz_data"`
-	QuizChecker *json.RawMessage `json:"quiz_checker"`
-	IoChecker   *json.RawMessage `json:"io_checker"`
-	CodeChecker *json.RawMessage `json:"code_checker"`
+	Uuid                uuid.UUID        `json:"uuid"`
+	OrderIndex          *int16           `json:"order_index"`
+	Reward              *int16           `json:"reward"`
+	Type                *ExerciseType    `json:"type"`
+	CodeData            *json.RawMessage `json:"code_data"`
+	QuizData            *json.RawMessage `json:"quiz_data"`
+	QuizChecker         *json.RawMessage `json:"quiz_checker"`
+	IoChecker           *json.RawMessage `json:"io_checker"`
+	CodeChecker         *json.RawMessage `json:"code_checker"`
+	AstChecker          *json.RawMessage `json:"ast_checker"`
+	LintChecker         *json.RawMessage `json:"lint_checker"`
+	PerformanceChecker  *json.RawMessage `json:"performance_checker"`
+	DifferentialChecker *json.RawMessage `json:"differential_checker"`
+	PassThreshold       *int16           `json:"pass_threshold"`
+	Deterministic       *bool            `json:"deterministic"`
+	RunConfig           *json.RawMessage `json:"run_config"`
+	Environment         *string          `json:"environment"`
+	ServerChecker       *json.RawMessage `json:"server_checker"`
+	Database            *json.RawMessage `json:"database"`
+	ResultSetChecker    *json.RawMessage `json:"result_set_checker"`
+	FilesystemChecker   *json.RawMessage `json:"filesystem_checker"`
+	TypecheckChecker    *json.RawMessage `json:"typecheck_checker"`
 }
 
 func (q *Queries) UpdateExercise(ctx context.Context, arg UpdateExerciseParams) (Exercise, error) {
@@ -383,6 +541,19 @@ func (q *Queries) UpdateExercise(ctx context.Context, arg UpdateExerciseParams)
 		arg.QuizChecker,
 		arg.IoChecker,
 		arg.CodeChecker,
+		arg.AstChecker,
+		arg.LintChecker,
+		arg.PerformanceChecker,
+		arg.DifferentialChecker,
+		arg.PassThreshold,
+		arg.Deterministic,
+		arg.RunConfig,
+		arg.Environment,
+		arg.ServerChecker,
+		arg.Database,
+		arg.ResultSetChecker,
+		arg.FilesystemChecker,
+		arg.TypecheckChecker,
 	)
 	var i Exercise
 	err := row.Scan(
@@ -399,6 +570,19 @@ func (q *Queries) UpdateExercise(ctx context.Context, arg UpdateExerciseParams)
 		&i.QuizChecker,
 		&i.IoChecker,
 		&i.CodeChecker,
+		&i.AstChecker,
+		&i.LintChecker,
+		&i.PerformanceChecker,
+		&i.DifferentialChecker,
+		&i.PassThreshold,
+		&i.Deterministic,
+		&i.RunConfig,
+		&i.Environment,
+		&i.ServerChecker,
+		&i.Database,
+		&i.ResultSetChecker,
+		&i.FilesystemChecker,
+		&i.TypecheckChecker,
 	)
 	return i, err
 }
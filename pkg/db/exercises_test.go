@@ -87,12 +87,13 @@ func createRandomExerciseWithType(t *testing.T, lesson *db.LessonWithTranslation
 	}
 
 	params := db.CreateExerciseParams{
-		LessonUuid: lesson.Uuid,
-		OrderIndex: 1,
-		Reward:     10,
-		Type:       exerciseType,
-		CodeData:   codeData,
-		QuizData:   quizData,
+		LessonUuid:    lesson.Uuid,
+		OrderIndex:    1,
+		Reward:        10,
+		Type:          exerciseType,
+		CodeData:      codeData,
+		QuizData:      quizData,
+		PassThreshold: 100,
 	}
 
 	exercise, err := testQueries.CreateExercise(context.Background(), params)
@@ -102,6 +103,7 @@ func createRandomExerciseWithType(t *testing.T, lesson *db.LessonWithTranslation
 	require.Equal(t, params.LessonUuid, exercise.LessonUuid)
 	require.Equal(t, params.OrderIndex, exercise.OrderIndex)
 	require.Equal(t, params.Reward, exercise.Reward)
+	require.Equal(t, params.PassThreshold, exercise.PassThreshold)
 	require.Equal(t, params.Type, exercise.Type)
 	require.Equal(t, params.CodeData, exercise.CodeData)
 	require.Equal(t, params.QuizData, exercise.QuizData)
@@ -477,6 +479,204 @@ func TestGetExerciseForSubmission(t *testing.T) {
 	require.Equal(t, exercise.Type, result.Type)
 }
 
+// TestGetExerciseForSubmissionConfig round-trips every JSON configuration of a code exercise
+// through CreateExercise and GetExerciseForSubmission.
+func TestGetExerciseForSubmissionConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		code     string
+		config   string
+		set      func(arg *db.CreateExerciseParams, config *json.RawMessage)
+		created  func(exercise db.Exercise) *json.RawMessage
+		got      func(row db.GetExerciseForSubmissionRow) *json.RawMessage
+	}{
+		{
+			name:     "AstChecker",
+			fileName: "main.py",
+			code:     "print('Hello World')",
+			config:   `{"rules": [{"kind": "require", "construct": "for"}]}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.AstChecker = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.AstChecker },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.AstChecker },
+		},
+		{
+			name:     "LintChecker",
+			fileName: "main.py",
+			code:     "print('Hello World')",
+			config:   `{"config": "[flake8]\nmax-line-length = 79\n", "required": true}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.LintChecker = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.LintChecker },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.LintChecker },
+		},
+		{
+			name:     "PerformanceChecker",
+			fileName: "main.py",
+			code:     "def solve(items):\n    return sorted(items)",
+			config:   `{"function": "solve", "generator": "def generate(n):\n    return [list(range(n))]\n", "sizes": [1000, 2000, 4000, 8000], "complexity": "O(n log n)"}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.PerformanceChecker = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.PerformanceChecker },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.PerformanceChecker },
+		},
+		{
+			name:     "DifferentialChecker",
+			fileName: "main.py",
+			code:     "a, b = map(int, input().split())\nprint(a + b)",
+			config:   `{"reference": "print(sum(map(int, input().split())))", "generator": "def generate(rng):\n    return '%d %d' % (rng.randint(-9, 9), rng.randint(-9, 9))\n", "cases": 10}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.DifferentialChecker = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.DifferentialChecker },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.DifferentialChecker },
+		},
+		{
+			name:     "RunConfig",
+			fileName: "app/__main__.py",
+			code:     "import os\nprint(os.environ['APP_MODE'])",
+			config:   `{"command": ["-m", "app"], "args": ["--verbose"], "env": {"APP_MODE": "test"}}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.RunConfig = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.RunConfig },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.RunConfig },
+		},
+		{
+			name:     "ServerChecker",
+			fileName: "main.py",
+			code:     "from http.server import HTTPServer, SimpleHTTPRequestHandler\nHTTPServer(('127.0.0.1', 8000), SimpleHTTPRequestHandler).serve_forever()",
+			config:   `{"script": "import urllib.request\nfrom test_utils import TestUtils\nTestUtils.success(urllib.request.urlopen('http://127.0.0.1:8000/').read().decode())\n", "port": 8000, "startup_timeout": 2}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.ServerChecker = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.ServerChecker },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.ServerChecker },
+		},
+		{
+			name:     "Database",
+			fileName: "main.sql",
+			code:     "SELECT name FROM users ORDER BY name;",
+			config:   `{"schema": "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);", "fixture": "INSERT INTO users (name) VALUES ('Ada'), ('Linus');"}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.Database = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.Database },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.Database },
+		},
+		{
+			name:     "ResultSetChecker",
+			fileName: "main.sql",
+			code:     "SELECT name FROM users ORDER BY name;",
+			config:   `{"expected": [{"columns": ["name"], "rows": [["Ada"], ["Linus"]]}], "ordered": true, "match_column_names": true}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.ResultSetChecker = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.ResultSetChecker },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.ResultSetChecker },
+		},
+		{
+			name:     "FilesystemChecker",
+			fileName: "main.sh",
+			code:     "mkdir -p logs\necho started > logs/app.log\nrm -rf tmp",
+			config:   `{"expectations": [{"path": "logs", "type": "directory"}, {"path": "logs/app.log", "contains": "started", "mode": "644"}, {"path": "tmp", "type": "absent"}]}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.FilesystemChecker = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.FilesystemChecker },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.FilesystemChecker },
+		},
+		{
+			name:     "TypecheckChecker",
+			fileName: "main.ts",
+			code:     "const greeting: string = 'Hello'\nconsole.log(greeting)",
+			config:   `{"fail_on_error": true, "weight": 2}`,
+			set:      func(arg *db.CreateExerciseParams, config *json.RawMessage) { arg.TypecheckChecker = config },
+			created:  func(exercise db.Exercise) *json.RawMessage { return exercise.TypecheckChecker },
+			got:      func(row db.GetExerciseForSubmissionRow) *json.RawMessage { return row.TypecheckChecker },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lesson := createRandomLesson(t, nil)
+			config := json.RawMessage(tt.config)
+
+			arg := db.CreateExerciseParams{
+				LessonUuid: lesson.Uuid,
+				OrderIndex: 1,
+				Reward:     10,
+				Type:       db.ExerciseTypeCode,
+				CodeData:   createCodeData(tt.fileName, tt.code),
+			}
+			tt.set(&arg, &config)
+
+			exercise, err := testQueries.CreateExercise(context.Background(), arg)
+			require.NoError(t, err)
+			assertJSONEqual(t, &config, tt.created(exercise), tt.name)
+
+			result, err := testQueries.GetExerciseForSubmission(context.Background(), exercise.Uuid)
+			require.NoError(t, err)
+			assertJSONEqual(t, &config, tt.got(result), tt.name)
+		})
+	}
+}
+
+func TestGetExerciseForSubmissionWithEnvironment(t *testing.T) {
+	lesson := createRandomLesson(t, nil)
+	environment := "data-science"
+
+	exercise, err := testQueries.CreateExercise(context.Background(), db.CreateExerciseParams{
+		LessonUuid:  lesson.Uuid,
+		OrderIndex:  1,
+		Reward:      10,
+		Type:        db.ExerciseTypeCode,
+		CodeData:    createCodeData("main.py", "import numpy\nprint(numpy.arange(3).sum())"),
+		Environment: &environment,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, exercise.Environment)
+	require.Equal(t, environment, *exercise.Environment)
+
+	result, err := testQueries.GetExerciseForSubmission(context.Background(), exercise.Uuid)
+	require.NoError(t, err)
+	require.NotNil(t, result.Environment)
+	require.Equal(t, environment, *result.Environment)
+}
+
+func TestGetExerciseForSubmissionScoring(t *testing.T) {
+	lesson := createRandomLesson(t, nil)
+
+	exercise, err := testQueries.CreateExercise(context.Background(), db.CreateExerciseParams{
+		LessonUuid:    lesson.Uuid,
+		OrderIndex:    1,
+		Reward:        20,
+		Type:          db.ExerciseTypeCode,
+		CodeData:      createCodeData("main.py", "print('Hello World')"),
+		PassThreshold: 70,
+	})
+	require.NoError(t, err)
+
+	result, err := testQueries.GetExerciseForSubmission(context.Background(), exercise.Uuid)
+	require.NoError(t, err)
+	require.Equal(t, int16(20), result.Reward)
+	require.Equal(t, int16(70), result.PassThreshold)
+}
+
+func TestGetExerciseForSubmissionDeterministic(t *testing.T) {
+	lesson := createRandomLesson(t, nil)
+
+	exercise, err := testQueries.CreateExercise(context.Background(), db.CreateExerciseParams{
+		LessonUuid:    lesson.Uuid,
+		OrderIndex:    1,
+		Reward:        10,
+		Type:          db.ExerciseTypeCode,
+		CodeData:      createCodeData("main.py", "print('Hello World')"),
+		PassThreshold: 100,
+		Deterministic: true,
+	})
+	require.NoError(t, err)
+	require.True(t, exercise.Deterministic)
+
+	result, err := testQueries.GetExerciseForSubmission(context.Background(), exercise.Uuid)
+	require.NoError(t, err)
+	require.True(t, result.Deterministic)
+
+	deterministic := false
+	updated, err := testQueries.UpdateExercise(context.Background(), db.UpdateExerciseParams{
+		Uuid:          exercise.Uuid,
+		Deterministic: &deterministic,
+	})
+	require.NoError(t, err)
+	require.False(t, updated.Deterministic)
+}
+
 func TestGetExerciseLessonCourse(t *testing.T) {
 	course := createRandomCourse(t)
 	lesson := createRandomLesson(t, &course)
@@ -0,0 +1 @@
+ALTER TABLE "exercises" DROP COLUMN IF EXISTS "ast_checker";
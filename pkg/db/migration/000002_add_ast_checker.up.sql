@@ -0,0 +1 @@
+ALTER TABLE "exercises" ADD COLUMN IF NOT EXISTS "ast_checker" JSONB NULL;
@@ -0,0 +1 @@
+ALTER TABLE "exercises" ADD COLUMN IF NOT EXISTS "lint_checker" JSONB NULL;
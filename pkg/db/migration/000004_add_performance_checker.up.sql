@@ -0,0 +1 @@
+ALTER TABLE "exercises" ADD COLUMN IF NOT EXISTS "performance_checker" JSONB NULL;
@@ -0,0 +1 @@
+ALTER TABLE "exercises" ADD COLUMN IF NOT EXISTS "differential_checker" JSONB NULL;
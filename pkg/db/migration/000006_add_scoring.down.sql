@@ -0,0 +1,2 @@
+ALTER TABLE "user_exercises" DROP COLUMN IF EXISTS "best_score";
+ALTER TABLE "exercises" DROP COLUMN IF EXISTS "pass_threshold";
@@ -0,0 +1,4 @@
+ALTER TABLE "exercises" ADD COLUMN IF NOT EXISTS "pass_threshold" SMALLINT NOT NULL DEFAULT 100;
+ALTER TABLE "user_exercises" ADD COLUMN IF NOT EXISTS "best_score" SMALLINT NOT NULL DEFAULT 0;
+
+UPDATE "user_exercises" SET "best_score" = 100 WHERE "completed_at" IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE "exercises" ADD COLUMN IF NOT EXISTS "deterministic" BOOLEAN NOT NULL DEFAULT FALSE;
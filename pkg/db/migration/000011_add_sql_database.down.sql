@@ -0,0 +1,2 @@
+ALTER TABLE "exercises" DROP COLUMN IF EXISTS "result_set_checker";
+ALTER TABLE "exercises" DROP COLUMN IF EXISTS "database";
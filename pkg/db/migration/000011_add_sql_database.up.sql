@@ -0,0 +1,2 @@
+ALTER TABLE "exercises" ADD COLUMN IF NOT EXISTS "database" JSONB NULL;
+ALTER TABLE "exercises" ADD COLUMN IF NOT EXISTS "result_set_checker" JSONB NULL;
@@ -88,19 +88,32 @@ type CourseTranslation struct {
 }
 
 type Exercise struct {
-	Uuid        uuid.UUID        `json:"uuid"`
-	CreatedAt   time.Time        `json:"created_at"`
-	ModifiedAt  time.Time        `json:"modified_at"`
-	DeletedAt   *time.Time       `json:"deleted_at"`
-	LessonUuid  uuid.UUID        `json:"lesson_uuid"`
-	OrderIndex  int16            `json:"order_index"`
-	Reward      int16            `json:"reward"`
-	Type        ExerciseType     `json:"type"`
-	CodeData    *json.RawMessage `json:"code_data"`
-	QuizData    *json.RawMessage `json:"quiz_data"`
-	QuizChecker *json.RawMessage `json:"quiz_checker"`
-	IoChecker   *json.RawMessage `json:"io_checker"`
-	CodeChecker *json.RawMessage `json:"code_checker"`
+	Uuid                uuid.UUID        `json:"uuid"`
+	CreatedAt           time.Time        `json:"created_at"`
+	ModifiedAt          time.Time        `json:"modified_at"`
+	DeletedAt           *time.Time       `json:"deleted_at"`
+	LessonUuid          uuid.UUID        `json:"lesson_uuid"`
+	OrderIndex          int16            `json:"order_index"`
+	Reward              int16            `json:"reward"`
+	Type                ExerciseType     `json:"type"`
+	CodeData            *json.RawMessage `json:"code_data"`
+	QuizData            *json.RawMessage `json:"quiz_data"`
+	QuizChecker         *json.RawMessage `json:"quiz_checker"`
+	IoChecker           *json.RawMessage `json:"io_checker"`
+	CodeChecker         *json.RawMessage `json:"code_checker"`
+	AstChecker          *json.RawMessage `json:"ast_checker"`
+	LintChecker         *json.RawMessage `json:"lint_checker"`
+	PerformanceChecker  *json.RawMessage `json:"performance_checker"`
+	DifferentialChecker *json.RawMessage `json:"differential_checker"`
+	PassThreshold       int16            `json:"pass_threshold"`
+	Deterministic       bool             `json:"deterministic"`
+	RunConfig           *json.RawMessage `json:"run_config"`
+	Environment         *string          `json:"environment"`
+	ServerChecker       *json.RawMessage `json:"server_checker"`
+	Database            *json.RawMessage `json:"database"`
+	ResultSetChecker    *json.RawMessage `json:"result_set_checker"`
+	FilesystemChecker   *json.RawMessage `json:"filesystem_checker"`
+	TypecheckChecker    *json.RawMessage `json:"typecheck_checker"`
 }
 
 type ExerciseTranslation struct {
@@ -165,6 +178,7 @@ type UserExercise struct {
 	Submission     json.RawMessage `json:"submission"`
 	Attempts       int32           `json:"attempts"`
 	CompletedAt    *time.Time      `json:"completed_at"`
+	BestScore      int16           `json:"best_score"`
 }
 
 type UserLesson struct {
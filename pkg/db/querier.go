@@ -75,6 +75,7 @@ type Querier interface {
 	UpdateLessonTranslation(ctx context.Context, arg UpdateLessonTranslationParams) (LessonTranslation, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserCourse(ctx context.Context, arg UpdateUserCourseParams) (UserCourse, error)
+	UpdateUserExerciseBestScore(ctx context.Context, arg UpdateUserExerciseBestScoreParams) (UserExercise, error)
 	UpdateUserExerciseSubmission(ctx context.Context, arg UpdateUserExerciseSubmissionParams) (UserExercise, error)
 	UpdateUserExerciseSubmissionWithAttempts(ctx context.Context, arg UpdateUserExerciseSubmissionWithAttemptsParams) (UserExercise, error)
 	UpdateUserLesson(ctx context.Context, arg UpdateUserLessonParams) (UserLesson, error)
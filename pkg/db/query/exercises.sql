@@ -22,9 +22,22 @@ INSERT INTO "exercises" (
   "quiz_data",
   "quiz_checker",
   "io_checker",
-  "code_checker"
+  "code_checker",
+  "ast_checker",
+  "lint_checker",
+  "performance_checker",
+  "differential_checker",
+  "pass_threshold",
+  "deterministic",
+  "run_config",
+  "environment",
+  "server_checker",
+  "database",
+  "result_set_checker",
+  "filesystem_checker",
+  "typecheck_checker"
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7, $8, $9
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
 )
 RETURNING *;
 
@@ -38,6 +51,19 @@ SET "order_index" = COALESCE(sqlc.narg('order_index'), "order_index"),
     "quiz_checker" = COALESCE(sqlc.narg('quiz_checker'), "quiz_checker"),
     "io_checker" = COALESCE(sqlc.narg('io_checker'), "io_checker"),
     "code_checker" = COALESCE(sqlc.narg('code_checker'), "code_checker"),
+    "ast_checker" = COALESCE(sqlc.narg('ast_checker'), "ast_checker"),
+    "lint_checker" = COALESCE(sqlc.narg('lint_checker'), "lint_checker"),
+    "performance_checker" = COALESCE(sqlc.narg('performance_checker'), "performance_checker"),
+    "differential_checker" = COALESCE(sqlc.narg('differential_checker'), "differential_checker"),
+    "pass_threshold" = COALESCE(sqlc.narg('pass_threshold'), "pass_threshold"),
+    "deterministic" = COALESCE(sqlc.narg('deterministic'), "deterministic"),
+    "run_config" = COALESCE(sqlc.narg('run_config'), "run_config"),
+    "environment" = COALESCE(sqlc.narg('environment'), "environment"),
+    "server_checker" = COALESCE(sqlc.narg('server_checker'), "server_checker"),
+    "database" = COALESCE(sqlc.narg('database'), "database"),
+    "result_set_checker" = COALESCE(sqlc.narg('result_set_checker'), "result_set_checker"),
+    "filesystem_checker" = COALESCE(sqlc.narg('filesystem_checker'), "filesystem_checker"),
+    "typecheck_checker" = COALESCE(sqlc.narg('typecheck_checker'), "typecheck_checker"),
     "modified_at" = NOW()
 WHERE "uuid" = $1
 RETURNING *;
@@ -61,7 +87,7 @@ SELECT COUNT(*) FROM "exercises"
 WHERE "deleted_at" IS NULL;
 
 -- name: GetExerciseForSubmission :one
-SELECT "courses"."subject", "exercises"."type", "exercises"."code_checker", "exercises"."io_checker", "exercises"."quiz_checker" FROM "courses"
+SELECT "courses"."subject", "exercises"."type", "exercises"."code_checker", "exercises"."io_checker", "exercises"."quiz_checker", "exercises"."ast_checker", "exercises"."lint_checker", "exercises"."performance_checker", "exercises"."differential_checker", "exercises"."reward", "exercises"."pass_threshold", "exercises"."deterministic", "exercises"."run_config", "exercises"."environment", "exercises"."server_checker", "exercises"."database", "exercises"."result_set_checker", "exercises"."filesystem_checker", "exercises"."typecheck_checker" FROM "courses"
 JOIN "lessons" ON "courses"."uuid" = "lessons"."course_uuid"
 JOIN "exercises" ON "lessons"."uuid" = "exercises"."lesson_uuid"
 WHERE "exercises"."uuid" = $1
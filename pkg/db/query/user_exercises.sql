@@ -40,11 +40,18 @@ SET "completed_at" = NOW()
 WHERE "user_uuid" = $1 AND "exercise_uuid" = $2
 RETURNING *;
 
+-- name: UpdateUserExerciseBestScore :one
+UPDATE "user_exercises"
+SET "best_score" = GREATEST("best_score", sqlc.arg('score')::smallint)
+WHERE "user_uuid" = $1 AND "exercise_uuid" = $2
+RETURNING *;
+
 -- name: ResetUserExercise :one
 UPDATE "user_exercises"
 SET "submission" = '{}'::jsonb,
     "attempts" = 0,
     "completed_at" = NULL,
+    "best_score" = 0,
     "last_accessed_at" = NOW()
 WHERE "user_uuid" = $1 AND "exercise_uuid" = $2
 RETURNING *;
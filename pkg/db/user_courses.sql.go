@@ -97,7 +97,7 @@ inserted_user_exercises AS (
     )
     SELECT $1, "exercise_uuid", '{}'::jsonb, 0, NULL
     FROM course_exercises
-    RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at
+    RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at, best_score
 )
 SELECT uuid, started_at, last_accessed_at, user_uuid, course_uuid, completed_at FROM inserted_user_course
 `
@@ -17,7 +17,7 @@ const completeUserExercise = `-- name: CompleteUserExercise :one
 UPDATE "user_exercises"
 SET "completed_at" = NOW()
 WHERE "user_uuid" = $1 AND "exercise_uuid" = $2
-RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at
+RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at, best_score
 `
 
 type CompleteUserExerciseParams struct {
@@ -37,6 +37,7 @@ func (q *Queries) CompleteUserExercise(ctx context.Context, arg CompleteUserExer
 		&i.Submission,
 		&i.Attempts,
 		&i.CompletedAt,
+		&i.BestScore,
 	)
 	return i, err
 }
@@ -51,7 +52,7 @@ INSERT INTO "user_exercises" (
 ) VALUES (
   $1, $2, $3, $4, $5
 )
-RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at
+RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at, best_score
 `
 
 type CreateUserExerciseParams struct {
@@ -80,12 +81,13 @@ func (q *Queries) CreateUserExercise(ctx context.Context, arg CreateUserExercise
 		&i.Submission,
 		&i.Attempts,
 		&i.CompletedAt,
+		&i.BestScore,
 	)
 	return i, err
 }
 
 const getUserExercise = `-- name: GetUserExercise :one
-SELECT uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at FROM "user_exercises"
+SELECT uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at, best_score FROM "user_exercises"
 WHERE "user_uuid" = $1 AND "exercise_uuid" = $2
 LIMIT 1
 `
@@ -107,6 +109,7 @@ func (q *Queries) GetUserExercise(ctx context.Context, arg GetUserExerciseParams
 		&i.Submission,
 		&i.Attempts,
 		&i.CompletedAt,
+		&i.BestScore,
 	)
 	return i, err
 }
@@ -116,9 +119,10 @@ UPDATE "user_exercises"
 SET "submission" = '{}'::jsonb,
     "attempts" = 0,
     "completed_at" = NULL,
+    "best_score" = 0,
     "last_accessed_at" = NOW()
 WHERE "user_uuid" = $1 AND "exercise_uuid" = $2
-RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at
+RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at, best_score
 `
 
 type ResetUserExerciseParams struct {
@@ -138,6 +142,37 @@ func (q *Queries) ResetUserExercise(ctx context.Context, arg ResetUserExercisePa
 		&i.Submission,
 		&i.Attempts,
 		&i.CompletedAt,
+		&i.BestScore,
+	)
+	return i, err
+}
+
+const updateUserExerciseBestScore = `-- name: UpdateUserExerciseBestScore :one
+UPDATE "user_exercises"
+SET "best_score" = GREATEST("best_score", $3::smallint)
+WHERE "user_uuid" = $1 AND "exercise_uuid" = $2
+RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at, best_score
+`
+
+type UpdateUserExerciseBestScoreParams struct {
+	UserUuid     uuid.UUID `json:"user_uuid"`
+	ExerciseUuid uuid.UUID `json:"exercise_uuid"`
+	Score        int16     `json:"score"`
+}
+
+func (q *Queries) UpdateUserExerciseBestScore(ctx context.Context, arg UpdateUserExerciseBestScoreParams) (UserExercise, error) {
+	row := q.db.QueryRow(ctx, updateUserExerciseBestScore, arg.UserUuid, arg.ExerciseUuid, arg.Score)
+	var i UserExercise
+	err := row.Scan(
+		&i.Uuid,
+		&i.StartedAt,
+		&i.LastAccessedAt,
+		&i.UserUuid,
+		&i.ExerciseUuid,
+		&i.Submission,
+		&i.Attempts,
+		&i.CompletedAt,
+		&i.BestScore,
 	)
 	return i, err
 }
@@ -151,7 +186,7 @@ WHERE "user_uuid" = $1
 AND "exercise_uuid" = $2 
 AND "exercises"."type" = $3 
 AND "exercises"."uuid" = "user_exercises"."exercise_uuid"
-RETURNING user_exercises.uuid, user_exercises.started_at, user_exercises.last_accessed_at, user_exercises.user_uuid, user_exercises.exercise_uuid, user_exercises.submission, user_exercises.attempts, user_exercises.completed_at
+RETURNING user_exercises.uuid, user_exercises.started_at, user_exercises.last_accessed_at, user_exercises.user_uuid, user_exercises.exercise_uuid, user_exercises.submission, user_exercises.attempts, user_exercises.completed_at, user_exercises.best_score
 `
 
 type UpdateUserExerciseSubmissionParams struct {
@@ -178,6 +213,7 @@ func (q *Queries) UpdateUserExerciseSubmission(ctx context.Context, arg UpdateUs
 		&i.Submission,
 		&i.Attempts,
 		&i.CompletedAt,
+		&i.BestScore,
 	)
 	return i, err
 }
@@ -188,7 +224,7 @@ SET "submission" = COALESCE($3, "submission"),
     "attempts" = "attempts" + 1,
     "last_accessed_at" = NOW()
 WHERE "user_uuid" = $1 AND "exercise_uuid" = $2
-RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at
+RETURNING uuid, started_at, last_accessed_at, user_uuid, exercise_uuid, submission, attempts, completed_at, best_score
 `
 
 type UpdateUserExerciseSubmissionWithAttemptsParams struct {
@@ -209,6 +245,7 @@ func (q *Queries) UpdateUserExerciseSubmissionWithAttempts(ctx context.Context,
 		&i.Submission,
 		&i.Attempts,
 		&i.CompletedAt,
+		&i.BestScore,
 	)
 	return i, err
 }
@@ -174,6 +174,29 @@ func TestCompleteUserExercise(t *testing.T) {
 	require.Equal(t, userExercise.ExerciseUuid, completeUserExercise.ExerciseUuid)
 }
 
+func TestUpdateUserExerciseBestScore(t *testing.T) {
+	userExercise := createRandomUserExercise(t)
+	require.Equal(t, int16(0), userExercise.BestScore)
+
+	for _, tc := range []struct {
+		score    int16
+		expected int16
+	}{
+		{score: 60, expected: 60},
+		{score: 40, expected: 60},
+		{score: 90, expected: 90},
+	} {
+		updatedUserExercise, err := testQueries.UpdateUserExerciseBestScore(context.Background(), db.UpdateUserExerciseBestScoreParams{
+			UserUuid:     userExercise.UserUuid,
+			ExerciseUuid: userExercise.ExerciseUuid,
+			Score:        tc.score,
+		})
+		require.NoError(t, err)
+		require.Equal(t, userExercise.Uuid, updatedUserExercise.Uuid)
+		require.Equal(t, tc.expected, updatedUserExercise.BestScore)
+	}
+}
+
 func TestResetUserExercise(t *testing.T) {
 	userExercise := createRandomUserExercise(t)
 
@@ -190,4 +213,5 @@ func TestResetUserExercise(t *testing.T) {
 	require.Equal(t, json.RawMessage(`{}`), resetUserExercise.Submission)
 	require.Equal(t, int32(0), resetUserExercise.Attempts)
 	require.Nil(t, resetUserExercise.CompletedAt)
+	require.Equal(t, int16(0), resetUserExercise.BestScore)
 }
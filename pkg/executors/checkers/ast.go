@@ -0,0 +1,83 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type ASTRuleKind string
+
+const (
+	ASTRuleRequire ASTRuleKind = "require"
+	ASTRuleForbid  ASTRuleKind = "forbid"
+)
+
+// ASTRule requires or forbids a construct in the submission.
+// Construct is one of the language neutral names understood by the drivers' analyzers:
+// for, while, loop, comprehension, if, function, class, call, import, try and return.
+// Name optionally narrows the match, e.g. the function "area" or the call "sorted".
+type ASTRule struct {
+	Kind      ASTRuleKind `json:"kind"`
+	Construct string      `json:"construct"`
+	Name      string      `json:"name,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	// Weight is the rule's weight within the checker.
+	Weight float64 `json:"weight,omitempty"`
+}
+
+type ASTChecker struct {
+	Rules  []ASTRule `json:"rules"`
+	Weight float64   `json:"weight,omitempty"`
+}
+
+// astAnalyzerInput is handed to the driver's analyzer script inside the sandbox.
+type astAnalyzerInput struct {
+	Files []string  `json:"files"`
+	Rules []ASTRule `json:"rules"`
+}
+
+// Input builds the analyzer input for the given source files (relative to the job directory).
+func (c *ASTChecker) Input(files []string) (string, error) {
+	input, err := json.Marshal(astAnalyzerInput{
+		Files: files,
+		Rules: c.Rules,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal ast analyzer input: %w", err)
+	}
+
+	return string(input), nil
+}
+
+// Check parses the analyzer output, one result per rule.
+// Rules the analyzer did not report on (e.g. because it crashed) are marked as failed.
+func (c *ASTChecker) Check(ctx context.Context, stdout string) []CheckerResult {
+	results := parseTestResults(stdout, CheckerTypeAST)
+	for i := range results {
+		if i < len(c.Rules) {
+			results[i].Weight = c.Rules[i].Weight
+		}
+	}
+
+	if len(results) < len(c.Rules) {
+		results = append(results, CheckerResult{
+			Type:    CheckerTypeAST,
+			Success: false,
+			Message: "Static analysis did not complete",
+		})
+	}
+
+	return results
+}
+
+// Unsupported is reported when the driver has no analyzer for its language.
+func (c *ASTChecker) Unsupported() []CheckerResult {
+	return []CheckerResult{
+		{
+			Type:    CheckerTypeAST,
+			Success: false,
+			Message: "Static analysis is not supported for this language",
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package checkers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestASTCheck(t *testing.T) {
+	checker := &ASTChecker{Rules: []ASTRule{
+		{Kind: ASTRuleRequire, Construct: "for", Weight: 2},
+		{Kind: ASTRuleForbid, Construct: "call", Name: "sorted"},
+	}}
+
+	stdout := "learner output\n" +
+		`{"is_test": true, "success": true, "message": "Found for", "file": "main.py", "line": 3}` + "\n" +
+		`{"is_test": true, "success": false, "message": "call sorted is not allowed", "file": "main.py", "line": 5}`
+
+	results := checker.Check(context.Background(), stdout)
+	require.Len(t, results, 2)
+	require.True(t, results[0].Success)
+	require.Equal(t, CheckerTypeAST, results[0].Type)
+	require.Equal(t, 3, results[0].Line)
+	require.Equal(t, 2.0, results[0].Weight)
+	require.False(t, results[1].Success)
+	require.Equal(t, "call sorted is not allowed", results[1].Message)
+}
+
+func TestASTCheckIncomplete(t *testing.T) {
+	checker := &ASTChecker{Rules: []ASTRule{
+		{Kind: ASTRuleRequire, Construct: "for"},
+		{Kind: ASTRuleRequire, Construct: "function", Name: "area"},
+	}}
+
+	results := checker.Check(context.Background(), `{"is_test": true, "success": true, "message": "Found for"}`)
+	require.Len(t, results, 2)
+	require.False(t, results[1].Success)
+	require.Equal(t, "Static analysis did not complete", results[1].Message)
+}
@@ -7,17 +7,28 @@ import (
 )
 
 type CodeChecker struct {
-	Code     string `json:"code"`
-	FileName string `json:"file_name"`
+	Code     string  `json:"code"`
+	FileName string  `json:"file_name"`
+	Weight   float64 `json:"weight,omitempty"`
 }
 
 type codeCheckerResult struct {
 	IsTest  bool   `json:"is_test"`
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	// Weight is the test case's weight within its checker.
+	Weight float64 `json:"weight,omitempty"`
 }
 
 func (c *CodeChecker) Check(ctx context.Context, stdout string) []CheckerResult {
+	return parseTestResults(stdout, CheckerTypeCode)
+}
+
+// parseTestResults collects the JSON test result lines a checker script printed to stdout.
+// Lines that are not test results (e.g. the learner's own prints) are ignored.
+func parseTestResults(stdout string, checkerType CheckerType) []CheckerResult {
 	lines := strings.Split(stdout, "\n")
 	results := make([]CheckerResult, 0)
 	for _, line := range lines {
@@ -31,9 +42,12 @@ func (c *CodeChecker) Check(ctx context.Context, stdout string) []CheckerResult
 		}
 
 		results = append(results, CheckerResult{
-			Type:    CheckerTypeCode,
+			Type:    checkerType,
 			Success: result.Success,
 			Message: result.Message,
+			File:    result.File,
+			Line:    result.Line,
+			Weight:  result.Weight,
 		})
 	}
 
@@ -0,0 +1,233 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultDifferentialCases     = 20
+	defaultDifferentialTimeLimit = 5
+	differentialCaseTimeout      = 1
+)
+
+// DifferentialChecker runs a hidden reference solution and the submission on generated inputs
+// and compares their outputs.
+type DifferentialChecker struct {
+	// Reference is the reference solution, reading its input from stdin like the submission.
+	Reference string `json:"reference"`
+	// Generator defines generate(rng), returning the stdin of a single case.
+	Generator string `json:"generator"`
+	// Cases is how many inputs are generated.
+	Cases int `json:"cases,omitempty"`
+	// Seed makes the generated inputs, and so the reported counterexamples, reproducible.
+	Seed int64 `json:"seed,omitempty"`
+	// TimeLimit is the sandbox time limit of the reference's run and of the submission's, in seconds.
+	TimeLimit int     `json:"time_limit,omitempty"`
+	Weight    float64 `json:"weight,omitempty"`
+}
+
+// differentialHarnessInput is handed to the driver's harness script inside the sandbox. The harness runs the
+// interpreter with Command on every input: either on Cases inputs it generates from Seed, or on the given Inputs.
+type differentialHarnessInput struct {
+	Command     []string `json:"command"`
+	Generate    bool     `json:"generate"`
+	Inputs      []string `json:"inputs,omitempty"`
+	Cases       int      `json:"cases,omitempty"`
+	Seed        int64    `json:"seed,omitempty"`
+	Budget      float64  `json:"budget"`
+	CaseTimeout float64  `json:"case_timeout"`
+}
+
+// differentialRun is reported by the harness for every input it ran the program on.
+// Aborted is set instead when the harness couldn't go on, e.g. because the generator failed.
+type differentialRun struct {
+	IsDiff  bool   `json:"is_diff"`
+	Aborted bool   `json:"aborted"`
+	Input   string `json:"input"`
+	Output  string `json:"output"`
+	Error   string `json:"error"`
+}
+
+// DifferentialCase is a generated input and the output of the reference solution for it.
+type DifferentialCase struct {
+	Input    string
+	Expected string
+}
+
+// TimeLimitSeconds is the sandbox time limit of each run, the reference's and the submission's.
+func (c *DifferentialChecker) TimeLimitSeconds() int {
+	if c.TimeLimit > 0 {
+		return c.TimeLimit
+	}
+	return defaultDifferentialTimeLimit
+}
+
+func (c *DifferentialChecker) cases() int {
+	if c.Cases > 0 {
+		return c.Cases
+	}
+	return defaultDifferentialCases
+}
+
+// ReferenceInput builds the harness input generating the inputs and running the reference solution,
+// written to program, on them. The reference runs in a sandbox of its own, so the submission can't read it.
+func (c *DifferentialChecker) ReferenceInput(program string) (string, error) {
+	return c.input(differentialHarnessInput{
+		Command:  []string{program},
+		Generate: true,
+		Cases:    c.cases(),
+		Seed:     c.Seed,
+	})
+}
+
+// Input builds the harness input running the submission on the inputs of the cases.
+// Command holds the interpreter arguments that start the submission, as for a regular run.
+func (c *DifferentialChecker) Input(command []string, cases []DifferentialCase) (string, error) {
+	inputs := make([]string, len(cases))
+	for i, testCase := range cases {
+		inputs[i] = testCase.Input
+	}
+
+	return c.input(differentialHarnessInput{
+		Command: command,
+		Inputs:  inputs,
+	})
+}
+
+func (c *DifferentialChecker) input(input differentialHarnessInput) (string, error) {
+	input.Budget = float64(c.TimeLimitSeconds()) * 0.8
+	input.CaseTimeout = differentialCaseTimeout
+
+	marshalled, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal differential harness input: %w", err)
+	}
+
+	return string(marshalled), nil
+}
+
+// ReferenceCases parses the reference run into the cases the submission is compared on.
+// It fails when the generator or the reference solution did.
+func (c *DifferentialChecker) ReferenceCases(referenceStdout string) ([]DifferentialCase, error) {
+	runs, err := parseDifferentialRuns(referenceStdout)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(runs) == 0 {
+		return nil, errors.New("the reference solution ran on no inputs")
+	}
+
+	cases := make([]DifferentialCase, len(runs))
+	for i, r := range runs {
+		if r.Error != "" {
+			return nil, fmt.Errorf("reference solution failed: %s", r.Error)
+		}
+		cases[i] = DifferentialCase{Input: r.Input, Expected: r.Output}
+	}
+
+	return cases, nil
+}
+
+// Check reports the smallest input the submission disagreed with the reference on,
+// given the submission's runs on the inputs of the cases.
+func (c *DifferentialChecker) Check(ctx context.Context, cases []DifferentialCase, stdout string) CheckerResult {
+	runs, err := parseDifferentialRuns(stdout)
+	if err != nil {
+		return c.Failed(err)
+	}
+
+	checked := 0
+	var counterexample *differentialRun
+	var expected string
+
+	for i, r := range runs {
+		if i >= len(cases) || r.Input != cases[i].Input {
+			return c.Failed(fmt.Errorf("unexpected input %q", r.Input))
+		}
+
+		checked++
+		if r.Error == "" && r.Output == cases[i].Expected {
+			continue
+		}
+		if counterexample == nil || len(r.Input) < len(counterexample.Input) {
+			counterexample = &runs[i]
+			expected = cases[i].Expected
+		}
+	}
+
+	if counterexample != nil {
+		message := fmt.Sprintf(
+			"Input: %s, Expected output: %s, Actual output: %s",
+			counterexample.Input,
+			expected,
+			counterexample.Output,
+		)
+		if counterexample.Error != "" {
+			message = fmt.Sprintf("%s, Error: %s", message, counterexample.Error)
+		}
+
+		return CheckerResult{
+			Type:    CheckerTypeDifferential,
+			Success: false,
+			Message: message,
+		}
+	}
+
+	// The reference may have run out of time before all Cases inputs were generated,
+	// so the submission is only held to the inputs the reference got through.
+	if checked < len(cases) {
+		return CheckerResult{
+			Type:    CheckerTypeDifferential,
+			Success: false,
+			Message: fmt.Sprintf("Differential testing did not complete: %d of %d inputs checked", checked, len(cases)),
+		}
+	}
+
+	return CheckerResult{
+		Type:    CheckerTypeDifferential,
+		Success: true,
+		Message: fmt.Sprintf("Output matches the reference solution on %d generated inputs", checked),
+	}
+}
+
+// parseDifferentialRuns returns the runs reported by the harness, or why it aborted.
+func parseDifferentialRuns(stdout string) ([]differentialRun, error) {
+	runs := make([]differentialRun, 0)
+	for _, line := range strings.Split(stdout, "\n") {
+		var r differentialRun
+		if err := json.Unmarshal([]byte(line), &r); err != nil || !r.IsDiff {
+			continue
+		}
+
+		if r.Aborted {
+			return nil, errors.New(r.Error)
+		}
+
+		runs = append(runs, r)
+	}
+
+	return runs, nil
+}
+
+// Failed is reported when the differential testing couldn't run, e.g. because the reference solution failed.
+func (c *DifferentialChecker) Failed(err error) CheckerResult {
+	return CheckerResult{
+		Type:    CheckerTypeDifferential,
+		Success: false,
+		Message: fmt.Sprintf("Differential testing failed: %s", err),
+	}
+}
+
+// Unsupported is reported when the driver has no harness for its language.
+func (c *DifferentialChecker) Unsupported() CheckerResult {
+	return CheckerResult{
+		Type:    CheckerTypeDifferential,
+		Success: false,
+		Message: "Differential testing is not supported for this language",
+	}
+}
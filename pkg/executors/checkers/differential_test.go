@@ -0,0 +1,111 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func differentialOutput(t *testing.T, runs ...differentialRun) string {
+	t.Helper()
+
+	lines := []string{"some learner output"}
+	for _, r := range runs {
+		r.IsDiff = true
+		line, err := json.Marshal(r)
+		require.NoError(t, err)
+		lines = append(lines, string(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDifferentialReferenceCases(t *testing.T) {
+	checker := &DifferentialChecker{Cases: 2}
+
+	cases, err := checker.ReferenceCases(differentialOutput(t,
+		differentialRun{Input: "1", Output: "2"},
+		differentialRun{Input: "3", Output: "6"},
+	))
+	require.NoError(t, err)
+	require.Equal(t, []DifferentialCase{{Input: "1", Expected: "2"}, {Input: "3", Expected: "6"}}, cases)
+
+	_, err = checker.ReferenceCases(differentialOutput(t))
+	require.EqualError(t, err, "the reference solution ran on no inputs")
+
+	_, err = checker.ReferenceCases(differentialOutput(t, differentialRun{Input: "1", Error: "ValueError: boom"}))
+	require.EqualError(t, err, "reference solution failed: ValueError: boom")
+
+	_, err = checker.ReferenceCases(differentialOutput(t, differentialRun{Aborted: true, Error: "generator failed: KeyError: 'n'"}))
+	require.EqualError(t, err, "generator failed: KeyError: 'n'")
+}
+
+func TestDifferentialInputOnlyHoldsInputs(t *testing.T) {
+	checker := &DifferentialChecker{Reference: "print(42)", Generator: "def generate(rng): ..."}
+
+	input, err := checker.Input([]string{"/work/main.py"}, []DifferentialCase{{Input: "1", Expected: "secret"}})
+	require.NoError(t, err)
+	require.NotContains(t, input, "secret")
+	require.NotContains(t, input, "print(42)")
+
+	var harnessInput differentialHarnessInput
+	require.NoError(t, json.Unmarshal([]byte(input), &harnessInput))
+	require.False(t, harnessInput.Generate)
+	require.Equal(t, []string{"/work/main.py"}, harnessInput.Command)
+	require.Equal(t, []string{"1"}, harnessInput.Inputs)
+}
+
+func TestDifferentialCheck(t *testing.T) {
+	checker := &DifferentialChecker{Cases: 3}
+	cases := []DifferentialCase{
+		{Input: "100", Expected: "200"},
+		{Input: "7", Expected: "14"},
+		{Input: "12", Expected: "24"},
+	}
+
+	r := checker.Check(context.Background(), cases, differentialOutput(t,
+		differentialRun{Input: "100", Output: "200"},
+		differentialRun{Input: "7", Output: "14"},
+		differentialRun{Input: "12", Output: "24"},
+	))
+	require.True(t, r.Success, r.Message)
+
+	// The shortest failing input is reported.
+	r = checker.Check(context.Background(), cases, differentialOutput(t,
+		differentialRun{Input: "100", Output: "100"},
+		differentialRun{Input: "7", Output: "", Error: "ValueError: boom"},
+		differentialRun{Input: "12", Output: "12"},
+	))
+	require.False(t, r.Success)
+	require.Equal(t, "Input: 7, Expected output: 14, Actual output: , Error: ValueError: boom", r.Message)
+
+	r = checker.Check(context.Background(), cases, differentialOutput(t,
+		differentialRun{Input: "100", Output: "200"},
+	))
+	require.False(t, r.Success)
+	require.Equal(t, "Differential testing did not complete: 1 of 3 inputs checked", r.Message)
+
+	r = checker.Check(context.Background(), cases, differentialOutput(t,
+		differentialRun{Input: "5", Output: "10"},
+	))
+	require.False(t, r.Success)
+	require.Contains(t, r.Message, "unexpected input")
+}
+
+func TestDifferentialCheckFewerReferenceCases(t *testing.T) {
+	// The reference ran out of time after two of the five inputs.
+	checker := &DifferentialChecker{Cases: 5}
+	cases := []DifferentialCase{
+		{Input: "1", Expected: "2"},
+		{Input: "3", Expected: "6"},
+	}
+
+	r := checker.Check(context.Background(), cases, differentialOutput(t,
+		differentialRun{Input: "1", Output: "2"},
+		differentialRun{Input: "3", Output: "6"},
+	))
+	require.True(t, r.Success, r.Message)
+	require.Equal(t, "Output matches the reference solution on 2 generated inputs", r.Message)
+}
@@ -0,0 +1,173 @@
+package checkers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+)
+
+type FileType string
+
+const (
+	FileTypeFile      FileType = "file"
+	FileTypeDirectory FileType = "directory"
+	FileTypeSymlink   FileType = "symlink"
+	FileTypeAbsent    FileType = "absent"
+	// FileTypeOther is reported for anything else that exists, e.g. a FIFO.
+	FileTypeOther FileType = "other"
+)
+
+// FileSystemChecker inspects the working directory after the submission has run,
+// e.g. for exercises asking to create directories and files.
+type FileSystemChecker struct {
+	// Expectations each report their own result.
+	Expectations []FileExpectation `json:"expectations"`
+	Weight       float64           `json:"weight,omitempty"`
+}
+
+// FileExpectation describes a single path in the working directory.
+type FileExpectation struct {
+	// Path is relative to the working directory of the submission.
+	Path string `json:"path"`
+	// Type is what the path should be, a file unless set.
+	Type FileType `json:"type,omitempty"`
+	// Content must equal the file's content, ignoring trailing newlines.
+	Content *string `json:"content,omitempty"`
+	// Contains must appear in the file's content.
+	Contains string `json:"contains,omitempty"`
+	// Mode is the expected permission bits in octal, e.g. "755".
+	Mode string `json:"mode,omitempty"`
+	// Message replaces the default description of the expectation.
+	Message string  `json:"message,omitempty"`
+	Weight  float64 `json:"weight,omitempty"`
+}
+
+// FileState is what the driver's inspector found at a path.
+type FileState struct {
+	Type    FileType
+	Mode    string
+	Content string
+}
+
+// Paths lists the paths the inspector has to look at, relative to the working directory.
+func (c *FileSystemChecker) Paths() []string {
+	paths := make([]string, len(c.Expectations))
+	for i, e := range c.Expectations {
+		paths[i] = cleanWorkPath(e.Path)
+	}
+	return paths
+}
+
+// Input renders the paths for the inspector, one per line.
+func (c *FileSystemChecker) Input() string {
+	return strings.Join(c.Paths(), "\n") + "\n"
+}
+
+// ParseInspection reads the inspector's output: one line per path holding the path, its type,
+// its mode and, for files, its base64 encoded content, separated by tabs.
+func ParseInspection(stdout string) map[string]FileState {
+	states := make(map[string]FileState)
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+
+		content, err := base64.StdEncoding.DecodeString(fields[3])
+		if err != nil {
+			continue
+		}
+
+		states[fields[0]] = FileState{
+			Type:    FileType(fields[1]),
+			Mode:    fields[2],
+			Content: string(content),
+		}
+	}
+	return states
+}
+
+func (c *FileSystemChecker) Check(ctx context.Context, stdout string) []CheckerResult {
+	states := ParseInspection(stdout)
+
+	results := make([]CheckerResult, len(c.Expectations))
+	for i, e := range c.Expectations {
+		p := cleanWorkPath(e.Path)
+		state, ok := states[p]
+		if !ok {
+			results[i] = e.result(false, fmt.Sprintf("Could not inspect %s", p))
+			continue
+		}
+
+		success, message := e.check(p, state)
+		results[i] = e.result(success, message)
+	}
+
+	return results
+}
+
+// Unsupported is reported when the driver has no inspector for its sandbox.
+func (c *FileSystemChecker) Unsupported() []CheckerResult {
+	return []CheckerResult{{
+		Type:    CheckerTypeFileSystem,
+		Success: false,
+		Message: "File system checks are not supported for this language",
+	}}
+}
+
+func (e FileExpectation) check(p string, state FileState) (bool, string) {
+	expectedType := e.Type
+	if expectedType == "" {
+		expectedType = FileTypeFile
+	}
+
+	if expectedType == FileTypeAbsent {
+		if state.Type != FileTypeAbsent {
+			return false, fmt.Sprintf("Expected %s to be removed, found a %s", p, state.Type)
+		}
+		return true, fmt.Sprintf("%s is removed", p)
+	}
+
+	if state.Type == FileTypeAbsent {
+		return false, fmt.Sprintf("Missing %s %s", expectedType, p)
+	}
+	if state.Type != expectedType {
+		return false, fmt.Sprintf("Expected %s to be a %s, found a %s", p, expectedType, state.Type)
+	}
+
+	if e.Mode != "" && strings.TrimLeft(e.Mode, "0") != strings.TrimLeft(state.Mode, "0") {
+		return false, fmt.Sprintf("Expected %s to have mode %s, found %s", p, e.Mode, state.Mode)
+	}
+
+	if e.Content != nil && strings.TrimRight(*e.Content, "\n") != strings.TrimRight(state.Content, "\n") {
+		return false, fmt.Sprintf("Expected %s to contain %q, found %q", p, *e.Content, state.Content)
+	}
+
+	if e.Contains != "" && !strings.Contains(state.Content, e.Contains) {
+		return false, fmt.Sprintf("Expected %s to contain %q", p, e.Contains)
+	}
+
+	return true, fmt.Sprintf("Found %s %s", expectedType, p)
+}
+
+func (e FileExpectation) result(success bool, message string) CheckerResult {
+	if e.Message != "" {
+		message = e.Message
+	}
+
+	return CheckerResult{
+		Type:    CheckerTypeFileSystem,
+		Success: success,
+		Message: message,
+		File:    cleanWorkPath(e.Path),
+		Weight:  e.Weight,
+	}
+}
+
+// cleanWorkPath makes p relative to the working directory, so "/work/a/../b" and "b" are the same path.
+func cleanWorkPath(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/work/")
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
@@ -0,0 +1,60 @@
+package checkers
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func inspection(lines ...[]string) string {
+	rendered := make([]string, len(lines))
+	for i, fields := range lines {
+		fields[3] = base64.StdEncoding.EncodeToString([]byte(fields[3]))
+		rendered[i] = strings.Join(fields, "\t")
+	}
+	return strings.Join(rendered, "\n")
+}
+
+func TestParseInspection(t *testing.T) {
+	stdout := inspection(
+		[]string{"logs", "directory", "755", ""},
+		[]string{"logs/app.log", "file", "644", "started\n\twith tabs\n"},
+		[]string{"tmp", "absent", "", ""},
+	) + "\nnot an inspection line\nbad\tfile\t644\t%%%"
+
+	require.Equal(t, map[string]FileState{
+		"logs":         {Type: FileTypeDirectory, Mode: "755"},
+		"logs/app.log": {Type: FileTypeFile, Mode: "644", Content: "started\n\twith tabs\n"},
+		"tmp":          {Type: FileTypeAbsent},
+	}, ParseInspection(stdout))
+}
+
+func TestFileSystemCheck(t *testing.T) {
+	content := "started"
+	checker := &FileSystemChecker{Expectations: []FileExpectation{
+		{Path: "/work/logs/", Type: FileTypeDirectory},
+		{Path: "logs/app.log", Content: &content, Mode: "0644"},
+		{Path: "logs/app.log", Mode: "755"},
+		{Path: "tmp", Type: FileTypeAbsent},
+		{Path: "missing.txt"},
+	}}
+	require.Equal(t, "logs\nlogs/app.log\nlogs/app.log\ntmp\nmissing.txt\n", checker.Input())
+
+	stdout := inspection(
+		[]string{"logs", "directory", "755", ""},
+		[]string{"logs/app.log", "file", "644", "started\n"},
+		[]string{"tmp", "directory", "755", ""},
+		[]string{"missing.txt", "absent", "", ""},
+	)
+
+	results := checker.Check(context.Background(), stdout)
+	require.Len(t, results, 5)
+	require.True(t, results[0].Success, results[0].Message)
+	require.True(t, results[1].Success, results[1].Message)
+	require.Equal(t, "Expected logs/app.log to have mode 755, found 644", results[2].Message)
+	require.Equal(t, "Expected tmp to be removed, found a directory", results[3].Message)
+	require.Equal(t, "Missing file missing.txt", results[4].Message)
+}
@@ -6,8 +6,9 @@ import (
 )
 
 type IOChecker struct {
-	Input          string `json:"input"`
-	ExpectedOutput string `json:"expected_output"`
+	Input          string  `json:"input"`
+	ExpectedOutput string  `json:"expected_output"`
+	Weight         float64 `json:"weight,omitempty"`
 }
 
 func (c *IOChecker) Check(ctx context.Context, stdout string) CheckerResult {
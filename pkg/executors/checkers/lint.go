@@ -0,0 +1,147 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type LintChecker struct {
+	// Config replaces the driver's default linter configuration,
+	// e.g. a [flake8] section for python or an eslint flat config for node.
+	Config string `json:"config,omitempty"`
+	// Required makes lint errors fail the submission. Otherwise findings are only reported.
+	Required bool    `json:"required"`
+	Weight   float64 `json:"weight,omitempty"`
+}
+
+// LintFinding is a single issue reported by a linter.
+type LintFinding struct {
+	File     string
+	Line     int
+	Column   int
+	Code     string
+	Message  string
+	Severity Severity
+}
+
+func (c *LintChecker) Check(ctx context.Context, findings []LintFinding) []CheckerResult {
+	if len(findings) == 0 {
+		return []CheckerResult{
+			{
+				Type:     CheckerTypeLint,
+				Success:  true,
+				Message:  "No lint issues found",
+				Severity: SeverityInfo,
+				Optional: !c.Required,
+			},
+		}
+	}
+
+	results := make([]CheckerResult, 0, len(findings))
+	for _, finding := range findings {
+		results = append(results, CheckerResult{
+			Type:     CheckerTypeLint,
+			Success:  false,
+			Message:  strings.TrimSpace(fmt.Sprintf("%s %s", finding.Code, finding.Message)),
+			Severity: finding.Severity,
+			Optional: !c.Required || finding.Severity != SeverityError,
+			File:     finding.File,
+			Line:     finding.Line,
+			Column:   finding.Column,
+		})
+	}
+
+	return results
+}
+
+// Failed is reported when the linter could not run at all.
+func (c *LintChecker) Failed(reason string) []CheckerResult {
+	return []CheckerResult{
+		{
+			Type:     CheckerTypeLint,
+			Success:  false,
+			Message:  fmt.Sprintf("Linter failed to run: %s", reason),
+			Severity: SeverityError,
+			Optional: !c.Required,
+		},
+	}
+}
+
+// ParseFlake8Output parses flake8 output produced with
+// --format=%(path)s:%(row)d:%(col)d:%(code)s:%(text)s
+// Pyflakes (F) and pycodestyle error (E) codes are errors, everything else is a warning.
+func ParseFlake8Output(stdout string) []LintFinding {
+	findings := make([]LintFinding, 0)
+	for _, line := range strings.Split(stdout, "\n") {
+		parts := strings.SplitN(line, ":", 5)
+		if len(parts) != 5 {
+			continue
+		}
+
+		row, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		col, _ := strconv.Atoi(parts[2])
+
+		severity := SeverityWarning
+		if strings.HasPrefix(parts[3], "E") || strings.HasPrefix(parts[3], "F") {
+			severity = SeverityError
+		}
+
+		findings = append(findings, LintFinding{
+			File:     strings.TrimPrefix(parts[0], "./"),
+			Line:     row,
+			Column:   col,
+			Code:     parts[3],
+			Message:  parts[4],
+			Severity: severity,
+		})
+	}
+
+	return findings
+}
+
+type eslintFileResult struct {
+	FilePath string `json:"filePath"`
+	Messages []struct {
+		RuleID   string `json:"ruleId"`
+		Severity int    `json:"severity"`
+		Message  string `json:"message"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+	} `json:"messages"`
+}
+
+// ParseESLintOutput parses eslint output produced with --format json.
+func ParseESLintOutput(stdout string) []LintFinding {
+	findings := make([]LintFinding, 0)
+
+	var files []eslintFileResult
+	if err := json.Unmarshal([]byte(stdout), &files); err != nil {
+		return findings
+	}
+
+	for _, file := range files {
+		for _, message := range file.Messages {
+			severity := SeverityWarning
+			if message.Severity == 2 {
+				severity = SeverityError
+			}
+
+			findings = append(findings, LintFinding{
+				File:     strings.TrimPrefix(file.FilePath, "/work/"),
+				Line:     message.Line,
+				Column:   message.Column,
+				Code:     message.RuleID,
+				Message:  message.Message,
+				Severity: severity,
+			})
+		}
+	}
+
+	return findings
+}
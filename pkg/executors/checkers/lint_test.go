@@ -0,0 +1,55 @@
+package checkers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlake8Output(t *testing.T) {
+	findings := ParseFlake8Output("./main.py:3:1:F401:'os' imported but unused\n" +
+		"main.py:10:80:W505:doc line too long (90 > 79 characters)\n" +
+		"flake8: not a finding\n")
+
+	require.Equal(t, []LintFinding{
+		{File: "main.py", Line: 3, Column: 1, Code: "F401", Message: "'os' imported but unused", Severity: SeverityError},
+		{File: "main.py", Line: 10, Column: 80, Code: "W505", Message: "doc line too long (90 > 79 characters)", Severity: SeverityWarning},
+	}, findings)
+}
+
+func TestParseESLintOutput(t *testing.T) {
+	findings := ParseESLintOutput(`[{"filePath": "/work/main.js", "messages": [
+		{"ruleId": "no-unused-vars", "severity": 2, "message": "'x' is defined but never used.", "line": 1, "column": 7},
+		{"ruleId": "semi", "severity": 1, "message": "Missing semicolon.", "line": 2, "column": 12}
+	]}]`)
+
+	require.Equal(t, []LintFinding{
+		{File: "main.js", Line: 1, Column: 7, Code: "no-unused-vars", Message: "'x' is defined but never used.", Severity: SeverityError},
+		{File: "main.js", Line: 2, Column: 12, Code: "semi", Message: "Missing semicolon.", Severity: SeverityWarning},
+	}, findings)
+
+	require.Empty(t, ParseESLintOutput("Oops! Something went wrong!"))
+}
+
+func TestLintCheck(t *testing.T) {
+	findings := []LintFinding{
+		{File: "main.py", Line: 3, Code: "F401", Message: "'os' imported but unused", Severity: SeverityError},
+		{File: "main.py", Line: 4, Code: "W291", Message: "trailing whitespace", Severity: SeverityWarning},
+	}
+
+	// Only errors of a required linter count against the submission.
+	results := (&LintChecker{Required: true}).Check(context.Background(), findings)
+	require.Len(t, results, 2)
+	require.False(t, results[0].Success)
+	require.False(t, results[0].Optional)
+	require.Equal(t, "F401 'os' imported but unused", results[0].Message)
+	require.True(t, results[1].Optional)
+
+	results = (&LintChecker{}).Check(context.Background(), findings)
+	require.True(t, results[0].Optional)
+
+	results = (&LintChecker{Required: true}).Check(context.Background(), nil)
+	require.Len(t, results, 1)
+	require.True(t, results[0].Success)
+}
@@ -0,0 +1,335 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	defaultPerformanceTimeLimit = 5
+	defaultPerformanceRepeats   = 3
+	defaultPerformanceTolerance = 3
+	minPerformanceMeasurements  = 3
+)
+
+type PerformanceChecker struct {
+	// Function is the name of the learner's function under test.
+	Function string `json:"function"`
+	// Module is the file defining Function, relative to the job directory. Defaults to the entry point.
+	Module string `json:"module,omitempty"`
+	// Generator defines generate(n), returning the list of arguments for an input of size n.
+	Generator string `json:"generator"`
+	// Sizes are the input sizes to measure, in increasing order.
+	Sizes []int `json:"sizes"`
+	// Complexity is the declared upper bound, e.g. "O(n log n)".
+	Complexity string `json:"complexity,omitempty"`
+	// Reference defines Function with the reference solution. When set, the learner's timings are compared to it.
+	Reference string `json:"reference,omitempty"`
+	// Tolerance is how many times slower than the reference the learner's solution may be at the largest size.
+	Tolerance float64 `json:"tolerance,omitempty"`
+	// Repeats is how many times each size is measured; the fastest run counts.
+	Repeats int `json:"repeats,omitempty"`
+	// TimeLimit is the sandbox time limit of the measurement run, in seconds.
+	TimeLimit int     `json:"time_limit,omitempty"`
+	Weight    float64 `json:"weight,omitempty"`
+}
+
+type Measurement struct {
+	Size          int     `json:"size"`
+	Time          float64 `json:"time"`
+	ReferenceTime float64 `json:"reference_time,omitempty"`
+}
+
+// performanceHarnessInput is handed to the driver's harness script inside the sandbox.
+// The harness times Function from Module, either the learner's or the reference solution.
+type performanceHarnessInput struct {
+	Module   string  `json:"module"`
+	Function string  `json:"function"`
+	Sizes    []int   `json:"sizes"`
+	Repeats  int     `json:"repeats"`
+	Budget   float64 `json:"budget"`
+}
+
+type performanceHarnessOutput struct {
+	IsPerf       bool          `json:"is_perf"`
+	Error        string        `json:"error,omitempty"`
+	Measurements []Measurement `json:"measurements"`
+}
+
+type complexityClass struct {
+	Name  string
+	Order int
+	// Degree ignores log factors, which a handful of timings can't reliably tell apart.
+	Degree int
+	F      func(n float64) float64
+}
+
+var complexityClasses = []complexityClass{
+	{Name: "O(1)", Order: 0, Degree: 0, F: func(n float64) float64 { return 1 }},
+	{Name: "O(log n)", Order: 1, Degree: 0, F: func(n float64) float64 { return math.Log2(n) }},
+	{Name: "O(n)", Order: 2, Degree: 1, F: func(n float64) float64 { return n }},
+	{Name: "O(n log n)", Order: 3, Degree: 1, F: func(n float64) float64 { return n * math.Log2(n) }},
+	{Name: "O(n^2)", Order: 4, Degree: 2, F: func(n float64) float64 { return n * n }},
+	{Name: "O(n^3)", Order: 5, Degree: 3, F: func(n float64) float64 { return n * n * n }},
+	{Name: "O(2^n)", Order: 6, Degree: 4, F: func(n float64) float64 { return math.Exp2(n) }},
+}
+
+// TimeLimitSeconds is the sandbox time limit for the measurement run.
+func (c *PerformanceChecker) TimeLimitSeconds() int {
+	if c.TimeLimit > 0 {
+		return c.TimeLimit
+	}
+	return defaultPerformanceTimeLimit
+}
+
+// Input builds the harness input timing the learner's function. The harness stops measuring once it
+// used most of the time limit, so slow solutions report fewer sizes instead of being killed.
+func (c *PerformanceChecker) Input(defaultModule string) (string, error) {
+	module := c.Module
+	if module == "" {
+		module = defaultModule
+	}
+
+	return c.input(module)
+}
+
+// ReferenceInput builds the harness input timing the reference solution, written to module.
+// The reference is timed in a sandbox of its own, so the learner's code never runs next to it.
+func (c *PerformanceChecker) ReferenceInput(module string) (string, error) {
+	return c.input(module)
+}
+
+func (c *PerformanceChecker) input(module string) (string, error) {
+	repeats := c.Repeats
+	if repeats <= 0 {
+		repeats = defaultPerformanceRepeats
+	}
+
+	input, err := json.Marshal(performanceHarnessInput{
+		Module:   module,
+		Function: c.Function,
+		Sizes:    c.Sizes,
+		Repeats:  repeats,
+		Budget:   float64(c.TimeLimitSeconds()) * 0.8,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal performance harness input: %w", err)
+	}
+
+	return string(input), nil
+}
+
+// Check fits the learner's timings, reported by the harness on stdout, and compares them to the
+// declared bound and to the reference timings, reported on referenceStdout when the checker has a reference.
+func (c *PerformanceChecker) Check(ctx context.Context, stdout string, referenceStdout string) CheckerResult {
+	output, ok := parsePerformanceOutput(stdout)
+	if !ok {
+		return c.failure("Performance measurement did not complete", nil)
+	}
+
+	if output.Error != "" {
+		return c.failure(fmt.Sprintf("Performance measurement failed: %s", output.Error), output.Measurements)
+	}
+
+	if len(output.Measurements) < minPerformanceMeasurements {
+		return c.failure(
+			fmt.Sprintf("Solution is too slow: only %d of %d input sizes finished in time", len(output.Measurements), len(c.Sizes)),
+			output.Measurements,
+		)
+	}
+
+	measured := fitComplexity(output.Measurements, func(m Measurement) float64 { return m.Time })
+	problems := make([]string, 0)
+
+	if c.Complexity != "" {
+		declared, ok := findComplexityClass(c.Complexity)
+		if !ok {
+			return c.failure(fmt.Sprintf("Unknown complexity bound %s", c.Complexity), output.Measurements)
+		}
+
+		// Log factors are compared loosely, like against the reference: an O(n) solution can fit as O(n log n).
+		if measured.Degree > declared.Degree {
+			problems = append(problems, fmt.Sprintf("expected at most %s", declared.Name))
+		}
+	}
+
+	if c.Reference != "" {
+		referenceOutput, ok := parsePerformanceOutput(referenceStdout)
+		if !ok {
+			return c.failure("Measuring the reference solution did not complete", output.Measurements)
+		}
+		if referenceOutput.Error != "" {
+			return c.failure(fmt.Sprintf("Measuring the reference solution failed: %s", referenceOutput.Error), output.Measurements)
+		}
+
+		compared := withReferenceTimes(output.Measurements, referenceOutput.Measurements)
+		if len(compared) < minPerformanceMeasurements {
+			return c.failure("Measuring the reference solution did not complete", output.Measurements)
+		}
+
+		reference := fitComplexity(compared, func(m Measurement) float64 { return m.ReferenceTime })
+		if measured.Degree > reference.Degree {
+			problems = append(problems, fmt.Sprintf("the reference solution is %s", reference.Name))
+		}
+
+		tolerance := c.Tolerance
+		if tolerance <= 0 {
+			tolerance = defaultPerformanceTolerance
+		}
+
+		last := compared[len(compared)-1]
+		if last.ReferenceTime > 0 && last.Time > tolerance*last.ReferenceTime {
+			problems = append(problems, fmt.Sprintf(
+				"%.1fx slower than the reference solution for n=%d",
+				last.Time/last.ReferenceTime,
+				last.Size,
+			))
+		}
+	}
+
+	if len(problems) > 0 {
+		return c.failure(
+			fmt.Sprintf("Measured %s: %s", measured.Name, strings.Join(problems, ", ")),
+			output.Measurements,
+		)
+	}
+
+	return CheckerResult{
+		Type:         CheckerTypePerformance,
+		Success:      true,
+		Message:      fmt.Sprintf("Measured %s", measured.Name),
+		Measurements: output.Measurements,
+	}
+}
+
+func (c *PerformanceChecker) failure(message string, measurements []Measurement) CheckerResult {
+	return CheckerResult{
+		Type:         CheckerTypePerformance,
+		Success:      false,
+		Message:      message,
+		Measurements: measurements,
+	}
+}
+
+// parsePerformanceOutput returns the last report of the harness, which holds every size measured so far.
+func parsePerformanceOutput(stdout string) (performanceHarnessOutput, bool) {
+	lines := strings.Split(stdout, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		var output performanceHarnessOutput
+		if err := json.Unmarshal([]byte(lines[i]), &output); err != nil {
+			continue
+		}
+
+		if output.IsPerf {
+			return output, true
+		}
+	}
+
+	return performanceHarnessOutput{}, false
+}
+
+// withReferenceTimes sets the reference time of the measurements at sizes the reference was timed at too,
+// and returns those measurements.
+func withReferenceTimes(measurements []Measurement, reference []Measurement) []Measurement {
+	times := make(map[int]float64, len(reference))
+	for _, m := range reference {
+		times[m.Size] = m.Time
+	}
+
+	compared := make([]Measurement, 0, len(measurements))
+	for i, m := range measurements {
+		t, ok := times[m.Size]
+		if !ok {
+			continue
+		}
+
+		measurements[i].ReferenceTime = t
+		compared = append(compared, measurements[i])
+	}
+
+	return compared
+}
+
+func findComplexityClass(name string) (complexityClass, bool) {
+	normalized := strings.ToLower(strings.ReplaceAll(name, " ", ""))
+	normalized = strings.ReplaceAll(normalized, "²", "^2")
+	normalized = strings.ReplaceAll(normalized, "³", "^3")
+	for _, class := range complexityClasses {
+		if strings.ToLower(strings.ReplaceAll(class.Name, " ", "")) == normalized {
+			return class, true
+		}
+	}
+	return complexityClass{}, false
+}
+
+// fitComplexity fits t(n) = a + c*f(n) for every complexity class and returns the best fit.
+// Residuals are relative to the measured time so large sizes don't dominate,
+// and a higher class has to fit clearly better to win over a lower one.
+func fitComplexity(measurements []Measurement, timeOf func(Measurement) float64) complexityClass {
+	best := complexityClasses[0]
+	bestResidual := math.Inf(1)
+
+	for _, class := range complexityClasses {
+		residual, ok := fitResidual(measurements, timeOf, class.F)
+		if !ok {
+			continue
+		}
+
+		if residual < bestResidual*0.8 {
+			best = class
+			bestResidual = residual
+		}
+	}
+
+	return best
+}
+
+func fitResidual(measurements []Measurement, timeOf func(Measurement) float64, f func(float64) float64) (float64, bool) {
+	// Weighted least squares with weights 1/t^2 on the model t = a + c*x.
+	var sw, sx, sy, sxx, sxy float64
+	for _, m := range measurements {
+		t := math.Max(timeOf(m), 1e-9)
+		x := f(float64(m.Size))
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return 0, false
+		}
+
+		w := 1 / (t * t)
+		sw += w
+		sx += w * x
+		sy += w * t
+		sxx += w * x * x
+		sxy += w * x * t
+	}
+
+	var a, c float64
+	det := sw*sxx - sx*sx
+	if math.Abs(det) < 1e-12 {
+		a = sy / sw
+	} else {
+		c = (sw*sxy - sx*sy) / det
+		a = (sy - c*sx) / sw
+	}
+
+	// A negative growth factor means the class doesn't describe the curve at all.
+	if c < 0 {
+		return 0, false
+	}
+
+	var residual float64
+	for _, m := range measurements {
+		t := math.Max(timeOf(m), 1e-9)
+		d := (t - a - c*f(float64(m.Size))) / t
+		residual += d * d
+	}
+
+	return residual, true
+}
+
+// Unsupported is reported when the driver has no harness for its language.
+func (c *PerformanceChecker) Unsupported() CheckerResult {
+	return c.failure("Performance checks are not supported for this language", nil)
+}
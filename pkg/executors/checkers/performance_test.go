@@ -0,0 +1,90 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var performanceSizes = []int{1000, 2000, 4000, 8000, 16000, 32000}
+
+// timings measures f at every size, scaled to realistic seconds.
+func timings(f func(n float64) float64) []Measurement {
+	measurements := make([]Measurement, len(performanceSizes))
+	for i, n := range performanceSizes {
+		measurements[i] = Measurement{Size: n, Time: 0.001 + f(float64(n))*1e-8}
+	}
+	return measurements
+}
+
+func harnessOutput(t *testing.T, measurements []Measurement) string {
+	t.Helper()
+
+	output, err := json.Marshal(performanceHarnessOutput{IsPerf: true, Measurements: measurements})
+	require.NoError(t, err)
+	return "some learner output\n" + string(output)
+}
+
+func linear(n float64) float64    { return n }
+func linearLog(n float64) float64 { return n * math.Log2(n) }
+func quadratic(n float64) float64 { return n * n }
+
+func TestFitComplexity(t *testing.T) {
+	time := func(m Measurement) float64 { return m.Time }
+
+	require.Equal(t, "O(1)", fitComplexity(timings(func(float64) float64 { return 0 }), time).Name)
+	require.Equal(t, 1, fitComplexity(timings(linear), time).Degree)
+	require.Equal(t, 1, fitComplexity(timings(linearLog), time).Degree)
+	require.Equal(t, "O(n^2)", fitComplexity(timings(quadratic), time).Name)
+	require.Equal(t, "O(n^3)", fitComplexity(timings(func(n float64) float64 { return n * n * n / 1000 }), time).Name)
+}
+
+func TestPerformanceCheckDeclaredBound(t *testing.T) {
+	checker := &PerformanceChecker{Function: "solve", Sizes: performanceSizes, Complexity: "O(n)"}
+
+	// Log factors can't be told apart, so a linear solution fitting as O(n log n) passes.
+	r := checker.Check(context.Background(), harnessOutput(t, timings(linearLog)), "")
+	require.True(t, r.Success, r.Message)
+
+	r = checker.Check(context.Background(), harnessOutput(t, timings(quadratic)), "")
+	require.False(t, r.Success)
+	require.Contains(t, r.Message, "expected at most O(n)")
+	require.Len(t, r.Measurements, len(performanceSizes))
+}
+
+func TestPerformanceCheckReference(t *testing.T) {
+	checker := &PerformanceChecker{Function: "solve", Sizes: performanceSizes, Reference: "def solve(a): ..."}
+
+	r := checker.Check(context.Background(), harnessOutput(t, timings(linear)), harnessOutput(t, timings(linear)))
+	require.True(t, r.Success, r.Message)
+	require.Equal(t, r.Measurements[0].Time, r.Measurements[0].ReferenceTime)
+
+	r = checker.Check(context.Background(), harnessOutput(t, timings(quadratic)), harnessOutput(t, timings(linear)))
+	require.False(t, r.Success)
+	require.Contains(t, r.Message, "the reference solution is")
+
+	// Only the sizes the reference finished are compared.
+	reference := timings(linear)[:2]
+	r = checker.Check(context.Background(), harnessOutput(t, timings(linear)), harnessOutput(t, reference))
+	require.False(t, r.Success)
+	require.Equal(t, "Measuring the reference solution did not complete", r.Message)
+
+	r = checker.Check(context.Background(), harnessOutput(t, timings(linear)), `{"is_perf": true, "measurements": [], "error": "NameError: solve"}`)
+	require.False(t, r.Success)
+	require.Contains(t, r.Message, "NameError: solve")
+}
+
+func TestPerformanceCheckIncomplete(t *testing.T) {
+	checker := &PerformanceChecker{Function: "solve", Sizes: performanceSizes}
+
+	r := checker.Check(context.Background(), "Traceback (most recent call last):", "")
+	require.False(t, r.Success)
+	require.Equal(t, "Performance measurement did not complete", r.Message)
+
+	r = checker.Check(context.Background(), harnessOutput(t, timings(linear)[:2]), "")
+	require.False(t, r.Success)
+	require.Contains(t, r.Message, "only 2 of 6 input sizes finished in time")
+}
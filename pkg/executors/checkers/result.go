@@ -3,13 +3,68 @@ package checkers
 type CheckerType string
 
 const (
-	CheckerTypeIO   CheckerType = "io"
-	CheckerTypeCode CheckerType = "code"
-	CheckerTypeQuiz CheckerType = "quiz"
+	CheckerTypeIO           CheckerType = "io"
+	CheckerTypeCode         CheckerType = "code"
+	CheckerTypeQuiz         CheckerType = "quiz"
+	CheckerTypeAST          CheckerType = "ast"
+	CheckerTypeLint         CheckerType = "lint"
+	CheckerTypePerformance  CheckerType = "performance"
+	CheckerTypeDifferential CheckerType = "differential"
+	CheckerTypeServer       CheckerType = "server"
+	CheckerTypeResultSet    CheckerType = "result_set"
+	CheckerTypeFileSystem   CheckerType = "filesystem"
+	CheckerTypeTypeCheck    CheckerType = "typecheck"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
 )
 
 type CheckerResult struct {
-	Type    CheckerType `json:"type"`
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
+	Type     CheckerType `json:"type"`
+	Success  bool        `json:"success"`
+	Message  string      `json:"message"`
+	Severity Severity    `json:"severity,omitempty"`
+	// Optional results are reported to the learner but don't fail the submission.
+	Optional bool   `json:"optional,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	// Weight is the result's share of the submission score, see Weigh.
+	Weight float64 `json:"weight,omitempty"`
+	// Measurements is the timing curve reported by the performance checker.
+	Measurements []Measurement `json:"measurements,omitempty"`
+}
+
+// Weigh spreads a checker's weight over its results in proportion to their own weights,
+// e.g. the weights of individual test cases. Unset weights count as 1.
+// Optional results don't take part in the score, so they get no weight.
+func Weigh(results []CheckerResult, weight float64) []CheckerResult {
+	total := 0.0
+	for _, r := range results {
+		if !r.Optional {
+			total += WeightOrDefault(r.Weight)
+		}
+	}
+
+	for i := range results {
+		if results[i].Optional {
+			results[i].Weight = 0
+			continue
+		}
+		results[i].Weight = WeightOrDefault(weight) * WeightOrDefault(results[i].Weight) / total
+	}
+
+	return results
+}
+
+func WeightOrDefault(weight float64) float64 {
+	if weight <= 0 {
+		return 1
+	}
+	return weight
 }
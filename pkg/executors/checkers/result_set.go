@@ -0,0 +1,116 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ResultSet holds the rows returned by one statement of a SQL submission.
+type ResultSet struct {
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+	// Truncated is set when the statement returned more rows than the driver reports.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ResultSetChecker compares the result sets of a SQL submission with the expected ones.
+type ResultSetChecker struct {
+	// Expected holds one result set per statement of the query file that returns rows.
+	Expected []ResultSet `json:"expected"`
+	// Ordered requires the rows in the expected order, otherwise any order is accepted.
+	Ordered bool `json:"ordered,omitempty"`
+	// MatchColumnNames also compares the column names, case-insensitively.
+	MatchColumnNames bool    `json:"match_column_names,omitempty"`
+	Weight           float64 `json:"weight,omitempty"`
+}
+
+// Check reports one result per expected result set.
+func (c *ResultSetChecker) Check(ctx context.Context, actual []ResultSet) []CheckerResult {
+	results := make([]CheckerResult, len(c.Expected))
+	for i, expected := range c.Expected {
+		if i >= len(actual) {
+			results[i] = c.failure(i, fmt.Sprintf("Expected %d result sets, got %d", len(c.Expected), len(actual)))
+			continue
+		}
+
+		if message := c.compare(expected, actual[i]); message != "" {
+			results[i] = c.failure(i, message)
+			continue
+		}
+
+		results[i] = CheckerResult{
+			Type:    CheckerTypeResultSet,
+			Success: true,
+			Message: fmt.Sprintf("Result set %d matches", i+1),
+		}
+	}
+
+	return results
+}
+
+// compare returns why the actual result set doesn't match, or an empty string when it does.
+func (c *ResultSetChecker) compare(expected ResultSet, actual ResultSet) string {
+	if len(expected.Columns) != len(actual.Columns) {
+		return fmt.Sprintf("Expected %d columns (%s), got %d (%s)",
+			len(expected.Columns), strings.Join(expected.Columns, ", "),
+			len(actual.Columns), strings.Join(actual.Columns, ", "))
+	}
+
+	if c.MatchColumnNames {
+		for i := range expected.Columns {
+			if !strings.EqualFold(expected.Columns[i], actual.Columns[i]) {
+				return fmt.Sprintf("Expected column %d to be named %s, got %s", i+1, expected.Columns[i], actual.Columns[i])
+			}
+		}
+	}
+
+	if actual.Truncated {
+		return fmt.Sprintf("Expected %d rows, got too many to compare", len(expected.Rows))
+	}
+	if len(expected.Rows) != len(actual.Rows) {
+		return fmt.Sprintf("Expected %d rows, got %d", len(expected.Rows), len(actual.Rows))
+	}
+
+	if c.Ordered {
+		for i := range expected.Rows {
+			if rowKey(expected.Rows[i]) != rowKey(actual.Rows[i]) {
+				return fmt.Sprintf("Expected row %d to be %s, got %s", i+1, rowKey(expected.Rows[i]), rowKey(actual.Rows[i]))
+			}
+		}
+		return ""
+	}
+
+	counts := make(map[string]int, len(expected.Rows))
+	for _, row := range expected.Rows {
+		counts[rowKey(row)]++
+	}
+	for _, row := range actual.Rows {
+		key := rowKey(row)
+		if counts[key] == 0 {
+			return fmt.Sprintf("Unexpected row %s", key)
+		}
+		counts[key]--
+	}
+
+	return ""
+}
+
+func (c *ResultSetChecker) failure(i int, message string) CheckerResult {
+	return CheckerResult{
+		Type:    CheckerTypeResultSet,
+		Success: false,
+		Message: fmt.Sprintf("Result set %d: %s", i+1, message),
+	}
+}
+
+// rowKey renders a row as JSON. Rows of the exercise and of the submission both come from JSON,
+// so numbers are float64 on both sides and 1 matches 1.0.
+func rowKey(row []any) string {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Sprint(row)
+	}
+	return string(data)
+}
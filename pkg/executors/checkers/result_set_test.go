@@ -0,0 +1,60 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// resultSet decodes a result set like the exercise and the driver do, from JSON.
+func resultSet(t *testing.T, data string) ResultSet {
+	t.Helper()
+
+	var rs ResultSet
+	require.NoError(t, json.Unmarshal([]byte(data), &rs))
+	return rs
+}
+
+func TestRowKey(t *testing.T) {
+	require.Equal(t,
+		rowKey(resultSet(t, `{"rows": [[1, "Ada", null]]}`).Rows[0]),
+		rowKey(resultSet(t, `{"rows": [[1.0, "Ada", null]]}`).Rows[0]),
+	)
+	require.NotEqual(t, rowKey([]any{"1"}), rowKey([]any{1.0}))
+	require.NotEqual(t, rowKey([]any{"a", "b"}), rowKey([]any{"b", "a"}))
+}
+
+func TestResultSetCheckOrdering(t *testing.T) {
+	expected := resultSet(t, `{"columns": ["name"], "rows": [["Ada"], ["Linus"], ["Ada"]]}`)
+	shuffled := resultSet(t, `{"columns": ["NAME"], "rows": [["Linus"], ["Ada"], ["Ada"]]}`)
+
+	results := (&ResultSetChecker{Expected: []ResultSet{expected}}).Check(context.Background(), []ResultSet{shuffled})
+	require.True(t, results[0].Success, results[0].Message)
+
+	results = (&ResultSetChecker{Expected: []ResultSet{expected}, Ordered: true}).Check(context.Background(), []ResultSet{shuffled})
+	require.False(t, results[0].Success)
+	require.Equal(t, `Result set 1: Expected row 1 to be ["Ada"], got ["Linus"]`, results[0].Message)
+
+	// Duplicates have to match in number, not just in kind.
+	deduplicated := resultSet(t, `{"columns": ["name"], "rows": [["Ada"], ["Linus"], ["Linus"]]}`)
+	results = (&ResultSetChecker{Expected: []ResultSet{expected}}).Check(context.Background(), []ResultSet{deduplicated})
+	require.False(t, results[0].Success)
+	require.Equal(t, `Result set 1: Unexpected row ["Linus"]`, results[0].Message)
+}
+
+func TestResultSetCheckColumns(t *testing.T) {
+	expected := resultSet(t, `{"columns": ["name"], "rows": [["Ada"]]}`)
+	actual := resultSet(t, `{"columns": ["first_name"], "rows": [["Ada"]]}`)
+
+	results := (&ResultSetChecker{Expected: []ResultSet{expected}}).Check(context.Background(), []ResultSet{actual})
+	require.True(t, results[0].Success, results[0].Message)
+
+	results = (&ResultSetChecker{Expected: []ResultSet{expected}, MatchColumnNames: true}).Check(context.Background(), []ResultSet{actual})
+	require.False(t, results[0].Success)
+
+	results = (&ResultSetChecker{Expected: []ResultSet{expected, expected}}).Check(context.Background(), []ResultSet{expected})
+	require.True(t, results[0].Success)
+	require.Equal(t, "Result set 2: Expected 2 result sets, got 1", results[1].Message)
+}
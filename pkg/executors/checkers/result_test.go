@@ -0,0 +1,27 @@
+package checkers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeigh(t *testing.T) {
+	results := Weigh([]CheckerResult{
+		{Success: true, Weight: 3},
+		{Success: false},
+		{Success: false, Optional: true, Weight: 5},
+	}, 8)
+
+	// The checker's weight is split 3:1 and the optional result gets none.
+	require.Equal(t, 6.0, results[0].Weight)
+	require.Equal(t, 2.0, results[1].Weight)
+	require.Zero(t, results[2].Weight)
+}
+
+func TestWeighDefaultsToOne(t *testing.T) {
+	results := Weigh([]CheckerResult{{Success: true}, {Success: true}}, 0)
+
+	require.Equal(t, 0.5, results[0].Weight)
+	require.Equal(t, 0.5, results[1].Weight)
+}
@@ -0,0 +1,105 @@
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultServerTimeLimit      = 5
+	defaultServerStartupTimeout = 2
+)
+
+// ServerChecker starts the learner's program as a server and runs a checker script against it.
+// Both run in a sandbox with loopback networking only.
+type ServerChecker struct {
+	// Script makes requests against localhost and prints test results like a CodeChecker.
+	Script string `json:"script"`
+	// Port is where the learner's server is expected to listen.
+	Port int `json:"port"`
+	// StartupTimeout is how long the server gets to start listening, in seconds.
+	StartupTimeout float64 `json:"startup_timeout,omitempty"`
+	// TimeLimit is the sandbox time limit of the whole run, in seconds.
+	TimeLimit int     `json:"time_limit,omitempty"`
+	Weight    float64 `json:"weight,omitempty"`
+}
+
+// serverHarnessInput is handed to the driver's harness script inside the sandbox.
+type serverHarnessInput struct {
+	Command        []string `json:"command"`
+	Port           int      `json:"port"`
+	StartupTimeout float64  `json:"startup_timeout"`
+	Budget         float64  `json:"budget"`
+}
+
+// serverHarnessError is reported by the harness when the server or the checker script failed.
+type serverHarnessError struct {
+	IsServer bool   `json:"is_server"`
+	Error    string `json:"error"`
+}
+
+// TimeLimitSeconds is the sandbox time limit for the server run.
+func (c *ServerChecker) TimeLimitSeconds() int {
+	if c.TimeLimit > 0 {
+		return c.TimeLimit
+	}
+	return defaultServerTimeLimit
+}
+
+// Input builds the harness input. Command holds the interpreter arguments that start the learner's server.
+func (c *ServerChecker) Input(command []string) (string, error) {
+	startupTimeout := c.StartupTimeout
+	if startupTimeout <= 0 {
+		startupTimeout = defaultServerStartupTimeout
+	}
+
+	input, err := json.Marshal(serverHarnessInput{
+		Command:        command,
+		Port:           c.Port,
+		StartupTimeout: startupTimeout,
+		Budget:         float64(c.TimeLimitSeconds()) * 0.8,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal server harness input: %w", err)
+	}
+
+	return string(input), nil
+}
+
+func (c *ServerChecker) Check(ctx context.Context, stdout string) []CheckerResult {
+	results := parseTestResults(stdout, CheckerTypeServer)
+
+	for _, line := range strings.Split(stdout, "\n") {
+		var r serverHarnessError
+		if err := json.Unmarshal([]byte(line), &r); err != nil || !r.IsServer {
+			continue
+		}
+
+		results = append(results, CheckerResult{
+			Type:    CheckerTypeServer,
+			Success: false,
+			Message: fmt.Sprintf("Server check failed: %s", r.Error),
+		})
+	}
+
+	if len(results) == 0 {
+		results = append(results, CheckerResult{
+			Type:    CheckerTypeServer,
+			Success: false,
+			Message: "Server checks did not report any results",
+		})
+	}
+
+	return results
+}
+
+// Unsupported is reported when the driver has no harness for its language.
+func (c *ServerChecker) Unsupported() CheckerResult {
+	return CheckerResult{
+		Type:    CheckerTypeServer,
+		Success: false,
+		Message: "Server checks are not supported for this language",
+	}
+}
@@ -0,0 +1,58 @@
+package checkers
+
+import (
+	"context"
+	"fmt"
+)
+
+// Diagnostic is a compiler message about the submission, e.g. a type error.
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Code     int      `json:"code,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// TypeCheckChecker reports the type errors found while compiling the submission.
+type TypeCheckChecker struct {
+	// FailOnError makes type errors fail the submission. Otherwise they are only reported.
+	FailOnError bool    `json:"fail_on_error,omitempty"`
+	Weight      float64 `json:"weight,omitempty"`
+}
+
+func (c *TypeCheckChecker) Check(ctx context.Context, diagnostics []Diagnostic) []CheckerResult {
+	results := make([]CheckerResult, 0)
+	for _, d := range diagnostics {
+		if d.Severity != SeverityError {
+			continue
+		}
+
+		message := d.Message
+		if d.Code != 0 {
+			message = fmt.Sprintf("TS%d: %s", d.Code, d.Message)
+		}
+
+		results = append(results, CheckerResult{
+			Type:     CheckerTypeTypeCheck,
+			Success:  false,
+			Message:  message,
+			Severity: SeverityError,
+			Optional: !c.FailOnError,
+			File:     d.File,
+			Line:     d.Line,
+			Column:   d.Column,
+		})
+	}
+
+	if len(results) == 0 {
+		results = append(results, CheckerResult{
+			Type:    CheckerTypeTypeCheck,
+			Success: true,
+			Message: "No type errors",
+		})
+	}
+
+	return results
+}
@@ -0,0 +1,171 @@
+package bash
+
+import (
+	"codim/pkg/executors/drivers/cmd"
+	"codim/pkg/executors/drivers/models"
+	"codim/pkg/utils/logger"
+	"context"
+)
+
+const (
+	// The rootfs only gets a curated set of coreutils, mounted one by one.
+	nsjailConfigTemplate = `name: "JOB-{{JOB_ID}}"
+mode: ONCE
+hostname: "JOB-{{JOB_ID}}"
+
+clone_newns: true
+
+clone_newpid: true
+clone_newipc: true
+clone_newuts: true
+clone_newuser: true
+
+clone_newnet: true
+iface_no_lo: {{NO_LOOPBACK}}
+
+cwd: "/work"
+mount_proc: false
+
+mount {
+  src: "/opt/nsjail/rootfs"
+  dst: "/"
+  is_bind: true
+  rw: false
+}
+
+mount { src: "/jobs/{{JOB_ID_FOLDER}}" dst: "/work" is_bind: true rw: true }
+
+mount { src: "/usr/bin/bash"       dst: "/usr/bin/bash"       is_bind: true rw: false }
+mount { src: "/usr/bin/sh"         dst: "/usr/bin/sh"         is_bind: true rw: false }
+mount { src: "/usr/bin/awk"        dst: "/usr/bin/awk"        is_bind: true rw: false }
+mount { src: "/usr/bin/base64"     dst: "/usr/bin/base64"     is_bind: true rw: false }
+mount { src: "/usr/bin/basename"   dst: "/usr/bin/basename"   is_bind: true rw: false }
+mount { src: "/usr/bin/cat"        dst: "/usr/bin/cat"        is_bind: true rw: false }
+mount { src: "/usr/bin/chmod"      dst: "/usr/bin/chmod"      is_bind: true rw: false }
+mount { src: "/usr/bin/cp"         dst: "/usr/bin/cp"         is_bind: true rw: false }
+mount { src: "/usr/bin/cut"        dst: "/usr/bin/cut"        is_bind: true rw: false }
+mount { src: "/usr/bin/date"       dst: "/usr/bin/date"       is_bind: true rw: false }
+mount { src: "/usr/bin/diff"       dst: "/usr/bin/diff"       is_bind: true rw: false }
+mount { src: "/usr/bin/dirname"    dst: "/usr/bin/dirname"    is_bind: true rw: false }
+mount { src: "/usr/bin/du"         dst: "/usr/bin/du"         is_bind: true rw: false }
+mount { src: "/usr/bin/echo"       dst: "/usr/bin/echo"       is_bind: true rw: false }
+mount { src: "/usr/bin/env"        dst: "/usr/bin/env"        is_bind: true rw: false }
+mount { src: "/usr/bin/false"      dst: "/usr/bin/false"      is_bind: true rw: false }
+mount { src: "/usr/bin/find"       dst: "/usr/bin/find"       is_bind: true rw: false }
+mount { src: "/usr/bin/grep"       dst: "/usr/bin/grep"       is_bind: true rw: false }
+mount { src: "/usr/bin/head"       dst: "/usr/bin/head"       is_bind: true rw: false }
+mount { src: "/usr/bin/ln"         dst: "/usr/bin/ln"         is_bind: true rw: false }
+mount { src: "/usr/bin/ls"         dst: "/usr/bin/ls"         is_bind: true rw: false }
+mount { src: "/usr/bin/mkdir"      dst: "/usr/bin/mkdir"      is_bind: true rw: false }
+mount { src: "/usr/bin/mv"         dst: "/usr/bin/mv"         is_bind: true rw: false }
+mount { src: "/usr/bin/printf"     dst: "/usr/bin/printf"     is_bind: true rw: false }
+mount { src: "/usr/bin/readlink"   dst: "/usr/bin/readlink"   is_bind: true rw: false }
+mount { src: "/usr/bin/rm"         dst: "/usr/bin/rm"         is_bind: true rw: false }
+mount { src: "/usr/bin/rmdir"      dst: "/usr/bin/rmdir"      is_bind: true rw: false }
+mount { src: "/usr/bin/sed"        dst: "/usr/bin/sed"        is_bind: true rw: false }
+mount { src: "/usr/bin/seq"        dst: "/usr/bin/seq"        is_bind: true rw: false }
+mount { src: "/usr/bin/sleep"      dst: "/usr/bin/sleep"      is_bind: true rw: false }
+mount { src: "/usr/bin/sort"       dst: "/usr/bin/sort"       is_bind: true rw: false }
+mount { src: "/usr/bin/stat"       dst: "/usr/bin/stat"       is_bind: true rw: false }
+mount { src: "/usr/bin/tail"       dst: "/usr/bin/tail"       is_bind: true rw: false }
+mount { src: "/usr/bin/tee"        dst: "/usr/bin/tee"        is_bind: true rw: false }
+mount { src: "/usr/bin/touch"      dst: "/usr/bin/touch"      is_bind: true rw: false }
+mount { src: "/usr/bin/tr"         dst: "/usr/bin/tr"         is_bind: true rw: false }
+mount { src: "/usr/bin/true"       dst: "/usr/bin/true"       is_bind: true rw: false }
+mount { src: "/usr/bin/uniq"       dst: "/usr/bin/uniq"       is_bind: true rw: false }
+mount { src: "/usr/bin/wc"         dst: "/usr/bin/wc"         is_bind: true rw: false }
+mount { src: "/usr/bin/xargs"      dst: "/usr/bin/xargs"      is_bind: true rw: false }
+mount { src: "/usr/bin/time" 	dst: "/usr/bin/time" 	is_bind: true rw: false }
+mount { src: "/usr/lib"         dst: "/usr/lib"         is_bind: true rw: false }
+mount { src: "/lib"             dst: "/lib"             is_bind: true rw: false }
+{{MOUNTS}}
+
+mount { dst: "/tmp" fstype: "tmpfs" rw: true options: "size=128m" }
+
+mount { dst: "/dev" fstype: "tmpfs" rw: false }
+mount { src: "/dev/null"    dst: "/dev/null"    is_bind: true rw: true }
+mount { src: "/dev/urandom" dst: "/dev/urandom" is_bind: true rw: false }
+
+rlimit_as: 512
+rlimit_cpu: {{TIME_LIMIT}}
+rlimit_nofile: 64
+rlimit_nproc: 16
+time_limit: {{TIME_LIMIT}}
+
+envar: "PATH=/usr/bin:/bin"
+envar: "HOME=/work"
+{{ENV}}
+
+exec_bin {
+  path: "/usr/bin/bash"
+{{ARGS}}
+}
+`
+	fileSystemInspectorFile = `
+while IFS= read -r path; do
+	target="/work/$path"
+	if [ -L "$target" ]; then
+		type=symlink
+	elif [ -d "$target" ]; then
+		type=directory
+	elif [ -f "$target" ]; then
+		type=file
+	elif [ -e "$target" ]; then
+		type=other
+	else
+		type=absent
+	fi
+
+	mode=""
+	if [ "$type" != absent ]; then
+		mode=$(stat -c %a "$target")
+	fi
+
+	content=""
+	if [ "$type" = file ]; then
+		content=$(head -c 65536 "$target" | base64 -w 0)
+	fi
+
+	printf '%s\t%s\t%s\t%s\n' "$path" "$type" "$mode" "$content"
+done < /checker/.fs_paths.txt
+`
+)
+
+var spec = cmd.Spec{
+	NsjailConfigTemplate: nsjailConfigTemplate,
+	SourceExtension:      "sh",
+	FileSystemInspector: cmd.Script{
+		FileName: ".fs_inspector.sh",
+		Content:  fileSystemInspectorFile,
+	},
+}
+
+type Driver struct {
+	logger    *logger.Logger
+	cmdPrefix string
+}
+
+func New(cmdPrefix string, logger *logger.Logger) *Driver {
+	return &Driver{
+		logger:    logger,
+		cmdPrefix: cmdPrefix,
+	}
+}
+
+func (d *Driver) Execute(ctx context.Context, executionRequest models.ExecutionRequest) (models.ExecuteResponse, error) {
+	return cmd.Execute(
+		ctx,
+		d.cmdPrefix,
+		spec,
+		executionRequest,
+	)
+}
+
+func (d *Driver) SetCmdPrefix(prefix string) error {
+	d.cmdPrefix = prefix
+	return nil
+}
+
+func (d *Driver) CmdPrefix() string {
+	return d.cmdPrefix
+}
@@ -2,29 +2,143 @@ package cmd
 
 import (
 	"bytes"
+	"codim/pkg/executors/checkers"
 	"codim/pkg/executors/drivers/models"
+	"codim/pkg/executors/explain"
 	"codim/pkg/fs"
 	"context"
 	"encoding/base64"
 	"fmt"
 	"os/exec"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 )
 
+const (
+	// astInputFileName is where runASTChecker leaves the rules for the driver's analyzer script.
+	astInputFileName = ".ast_rules.json"
+	// performanceInputFileName is where runPerformanceChecker leaves the input for the driver's harness script.
+	performanceInputFileName = ".perf_input.json"
+	// differentialInputFileName is where runDifferentialChecker leaves the input for the driver's harness script.
+	differentialInputFileName = ".diff_input.json"
+	// serverInputFileName is where runServerChecker leaves the input for the driver's harness script.
+	serverInputFileName = ".server_input.json"
+	// fileSystemInputFileName is where runFileSystemChecker leaves the paths for the driver's inspector script.
+	fileSystemInputFileName = ".fs_paths.txt"
+	// fileSystemTimeLimit is the sandbox time limit, in seconds, of the file system inspection.
+	fileSystemTimeLimit = 2
+	// databaseSchemaFileName and databaseFixtureFileName are where Execute leaves the database of the request for the driver's runner.
+	databaseSchemaFileName  = ".schema.sql"
+	databaseFixtureFileName = ".fixture.sql"
+	// defaultTimeLimit is the sandbox time limit, in seconds, of runs that don't set their own.
+	defaultTimeLimit = 1
+	// killedExitCode and cpuLimitExitCode are how nsjail reports a run killed at its time limit,
+	// by SIGKILL and SIGXCPU respectively.
+	killedExitCode   = 128 + 9
+	cpuLimitExitCode = 128 + 24
+	// environmentMountPath is where the package environment of the request is mounted in the sandbox.
+	environmentMountPath = "/env"
+	// checkerMountPath is where the checker folder of the job is mounted in the sandbox, read-only.
+	// Checker scripts and their inputs live there rather than in /work, where the submission could replace or read them.
+	checkerMountPath = "/checker"
+)
+
+// Spec holds the language specific pieces a driver hands to Execute.
+type Spec struct {
+	NsjailConfigTemplate string
+	TestUtilsFile        string
+	// SourceExtension is the extension (without the dot) of the driver's source files.
+	SourceExtension string
+	// ASTAnalyzer evaluates checkers.ASTChecker rules using the language's own parser.
+	ASTAnalyzer Script
+	// Linter reports style issues for checkers.LintChecker.
+	Linter Linter
+	// PerformanceHarness times the learner's function for checkers.PerformanceChecker.
+	PerformanceHarness Script
+	// DifferentialHarness compares the submission to a reference solution for checkers.DifferentialChecker.
+	DifferentialHarness Script
+	// ServerHarness starts the submission as a server and runs the checker script of checkers.ServerChecker against it.
+	ServerHarness Script
+	// Environment exposes the preinstalled package environments to the submission.
+	Environment Environment
+	// FileSystemInspector reports the state of the working directory for checkers.FileSystemChecker.
+	FileSystemInspector Script
+	// Runner starts the submission when the interpreter can't run it directly, e.g. SQL.
+	// It gets the submission's own interpreter arguments.
+	Runner Script
+	// Output post-processes the submission's run before the checkers see it.
+	Output func(response *models.ExecuteResponse)
+	// Traceback parses the uncaught exception of a failed run out of its stderr.
+	Traceback func(stderr string) *models.RuntimeError
+	// Explanations explain the common mistakes among the errors Traceback parses.
+	Explanations explain.Rules
+	// TimeLimit replaces defaultTimeLimit for the submission's own run, e.g. when a compile phase comes first.
+	TimeLimit int
+}
+
+// Environment describes where a driver's package environments live and how the interpreter finds them.
+type Environment struct {
+	// Root holds one read-only package directory per environment, built offline into the worker image.
+	Root string
+	// Variable points the interpreter at the packages, e.g. PYTHONPATH.
+	Variable string
+	// Path is the value of Variable inside the sandbox, at or below environmentMountPath.
+	Path string
+}
+
+// Linter describes how a driver runs its linter inside the sandbox.
+type Linter struct {
+	// Args are passed to the interpreter, followed by the submitted source files.
+	Args []string
+	// ConfigFileName is where the linter configuration is written, relative to the checker folder.
+	ConfigFileName string
+	// DefaultConfig is used when the exercise doesn't configure the linter.
+	DefaultConfig string
+	// Parse extracts the findings from the linter's stdout.
+	Parse func(stdout string) []checkers.LintFinding
+}
+
+// Script is a helper program a driver runs inside the sandbox.
+type Script struct {
+	FileName string
+	Content  string
+	// Flags are passed to the interpreter before the script path.
+	Flags []string
+}
+
 func Execute(
 	ctx context.Context,
 	cmdPrefix string,
-	nsjailConfigTemplate string,
-	testUtilsFile string,
+	spec Spec,
 	executionRequest models.ExecutionRequest,
 ) (models.ExecuteResponse, error) {
+	setupStart := time.Now()
 	jobIDStr := executionRequest.JobID.String()
 	jobPath := fmt.Sprintf("/jobs/%s", jobIDStr)
 
+	if executionRequest.Run != nil {
+		if err := executionRequest.Run.Validate(); err != nil {
+			return models.ExecuteResponse{}, fmt.Errorf("invalid run config: %w", err)
+		}
+	}
+
+	if executionRequest.Environment != "" {
+		if err := CheckEnvironment(ctx, cmdPrefix, spec, executionRequest.Environment); err != nil {
+			return models.ExecuteResponse{}, err
+		}
+	}
+
 	// Build nsjail config with replaced placeholders
-	config := prepareNsjailConfig(nsjailConfigTemplate, jobIDStr, jobIDStr, executionRequest.EntryPoint)
+	config := withEnvironment(spec.NsjailConfigTemplate, spec, executionRequest)
+	if spec.TimeLimit > 0 {
+		config = withTimeLimit(config, spec.TimeLimit)
+	}
+	config = prepareNsjailConfig(config, jobIDStr, jobIDStr, runArgs(spec, executionRequest)...)
 
 	// Create job directory in container
 	if err := CreateJobDirectory(ctx, cmdPrefix, jobPath); err != nil {
@@ -46,6 +160,12 @@ func Execute(
 		return models.ExecuteResponse{}, fmt.Errorf("failed to write files: %w", err)
 	}
 
+	if err := writeRunnerFiles(ctx, cmdPrefix, spec, executionRequest, jobPath); err != nil {
+		return models.ExecuteResponse{}, fmt.Errorf("failed to write runner files: %w", err)
+	}
+
+	setupTime := time.Since(setupStart)
+
 	// Execute nsjail
 	r, err := ExecuteNsjail(ctx, cmdPrefix, cfgPath)
 
@@ -53,21 +173,64 @@ func Execute(
 		return models.ExecuteResponse{}, err
 	}
 
-	runCheckers(
+	r.SetupTime = setupTime.Seconds()
+
+	if spec.Output != nil {
+		spec.Output(&r)
+	}
+
+	if r.ExitCode != 0 && spec.Traceback != nil {
+		r.Error = parseRuntimeError(spec, executionRequest, r.Stderr)
+	}
+
+	if r.Error != nil {
+		r.Error.Explanation = spec.Explanations.Explain(r.Error)
+	}
+
+	timeLimit := defaultTimeLimit
+	if spec.TimeLimit > 0 {
+		timeLimit = spec.TimeLimit
+	}
+	r.LimitExceeded = limitExceeded(r, timeLimit)
+
+	// A checker that couldn't run leaves the results incomplete, so the job fails and is retried
+	// rather than graded on the checkers that did.
+	err = runCheckers(
 		ctx,
 		executionRequest,
 		&r,
 		cmdPrefix,
-		nsjailConfigTemplate,
+		spec,
 		jobPath,
-		testUtilsFile,
 	)
+	if err != nil {
+		return models.ExecuteResponse{}, fmt.Errorf("failed to run checkers: %w", err)
+	}
+
+	return r, nil
+}
+
+// CheckEnvironment makes sure the package environment exists on this worker.
+func CheckEnvironment(ctx context.Context, cmdPrefix string, spec Spec, name string) error {
+	if spec.Environment.Root == "" {
+		return fmt.Errorf("driver doesn't support package environments")
+	}
+
+	if !models.ValidEnvironmentName(name) {
+		return fmt.Errorf("invalid environment name %q", name)
+	}
+
+	cmd := executeCommand(ctx, cmdPrefix, "test", "-d", path.Join(spec.Environment.Root, name))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("environment %s is not installed", name)
+	}
 
-	return r, err
+	return nil
 }
 
+// DeleteJobDirectory runs even when ctx is done, so cancelled jobs are cleaned up too.
 func DeleteJobDirectory(ctx context.Context, cmdPrefix string, jobPath string) error {
-	cmd := executeCommand(ctx, cmdPrefix, "rm", "-rf", jobPath)
+	cmd := executeCommand(context.WithoutCancel(ctx), cmdPrefix, "rm", "-rf", jobPath)
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to delete job directory %s: %w", jobPath, err)
@@ -122,8 +285,9 @@ func WriteEntry(ctx context.Context, cmdPrefix string, basePath string, entry fs
 	return nil
 }
 
+// DeleteFile runs even when ctx is done, like DeleteJobDirectory.
 func DeleteFile(ctx context.Context, cmdPrefix string, filePath string) error {
-	cmd := executeCommand(ctx, cmdPrefix, "rm", "-f", filePath)
+	cmd := executeCommand(context.WithoutCancel(ctx), cmdPrefix, "rm", "-f", filePath)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to delete config file %s: %w", filePath, err)
 	}
@@ -210,13 +374,86 @@ func runCheckers(
 	request models.ExecutionRequest,
 	response *models.ExecuteResponse,
 	cmdPrefix string,
-	nsjailConfigTemplate string,
+	spec Spec,
 	jobPath string,
-	testUtilsFile string,
 ) error {
 	if request.IOChecker != nil {
 		r := request.IOChecker.Check(ctx, response.Stdout)
-		response.CheckerResults = append(response.CheckerResults, r)
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh([]checkers.CheckerResult{r}, request.IOChecker.Weight)...)
+	}
+
+	if request.ResultSetChecker != nil {
+		rs := request.ResultSetChecker.Check(ctx, response.ResultSets)
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh(rs, request.ResultSetChecker.Weight)...)
+	}
+
+	if request.TypeCheckChecker != nil {
+		rs := request.TypeCheckChecker.Check(ctx, response.Diagnostics)
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh(rs, request.TypeCheckChecker.Weight)...)
+	}
+
+	if usesCheckerFolder(request) {
+		checkerPath := folderPath(checkerFolder(request))
+		if err := CreateJobDirectory(ctx, cmdPrefix, checkerPath); err != nil {
+			return fmt.Errorf("failed to create checker directory: %w", err)
+		}
+
+		defer DeleteJobDirectory(ctx, cmdPrefix, checkerPath)
+	}
+
+	// The code checker leaves its tests in the working directory, so it is inspected first.
+	if request.FileSystemChecker != nil {
+		rs, err := runFileSystemChecker(ctx, request, cmdPrefix, spec)
+		if err != nil {
+			return err
+		}
+
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh(rs, request.FileSystemChecker.Weight)...)
+	}
+
+	if request.ASTChecker != nil {
+		rs, err := runASTChecker(ctx, request, cmdPrefix, spec)
+		if err != nil {
+			return err
+		}
+
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh(rs, request.ASTChecker.Weight)...)
+	}
+
+	if request.LintChecker != nil {
+		rs, err := runLintChecker(ctx, request, cmdPrefix, spec)
+		if err != nil {
+			return err
+		}
+
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh(rs, request.LintChecker.Weight)...)
+	}
+
+	if request.PerformanceChecker != nil {
+		r, err := runPerformanceChecker(ctx, request, cmdPrefix, spec)
+		if err != nil {
+			return err
+		}
+
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh([]checkers.CheckerResult{r}, request.PerformanceChecker.Weight)...)
+	}
+
+	if request.DifferentialChecker != nil {
+		r, err := runDifferentialChecker(ctx, request, cmdPrefix, spec)
+		if err != nil {
+			return err
+		}
+
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh([]checkers.CheckerResult{r}, request.DifferentialChecker.Weight)...)
+	}
+
+	if request.ServerChecker != nil {
+		rs, err := runServerChecker(ctx, request, cmdPrefix, spec)
+		if err != nil {
+			return err
+		}
+
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh(rs, request.ServerChecker.Weight)...)
 	}
 
 	if request.CodeChecker != nil {
@@ -227,14 +464,18 @@ func runCheckers(
 		}
 
 		testUtilsFilePath := fmt.Sprintf("%s/%s", jobPath, "test_utils.py")
-		err = WriteFile(ctx, cmdPrefix, testUtilsFilePath, testUtilsFile)
+		err = WriteFile(ctx, cmdPrefix, testUtilsFilePath, spec.TestUtilsFile)
 		if err != nil {
 			return err
 		}
 
 		testJobId := fmt.Sprintf("%s-tests", request.JobID.String())
 		cfgPath := fmt.Sprintf("/tmp/config-%s.cfg", testJobId)
-		config := prepareNsjailConfig(nsjailConfigTemplate, testJobId, request.JobID.String(), request.CodeChecker.FileName)
+		config := withEnvironment(spec.NsjailConfigTemplate, spec, request)
+		if spec.TimeLimit > 0 {
+			config = withTimeLimit(config, spec.TimeLimit)
+		}
+		config = prepareNsjailConfig(config, testJobId, request.JobID.String(), withRunner(spec, workPath(request.CodeChecker.FileName))...)
 
 		err = CreateConfigFile(ctx, cmdPrefix, cfgPath, config)
 		if err != nil {
@@ -250,15 +491,552 @@ func runCheckers(
 
 		rs := request.CodeChecker.Check(ctx, r.Stdout)
 
-		response.CheckerResults = append(response.CheckerResults, rs...)
+		response.CheckerResults = append(response.CheckerResults, checkers.Weigh(rs, request.CodeChecker.Weight)...)
+	}
+
+	return nil
+}
+
+// runASTChecker runs the driver's analyzer over the submitted source files inside the sandbox.
+func runASTChecker(
+	ctx context.Context,
+	request models.ExecutionRequest,
+	cmdPrefix string,
+	spec Spec,
+) ([]checkers.CheckerResult, error) {
+	if spec.ASTAnalyzer.Content == "" {
+		return request.ASTChecker.Unsupported(), nil
+	}
+
+	input, err := request.ASTChecker.Input(sourceFiles(request.Source, spec.SourceExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	folder := checkerFolder(request)
+	if err := WriteFile(ctx, cmdPrefix, fmt.Sprintf("%s/%s", folderPath(folder), astInputFileName), input); err != nil {
+		return nil, err
+	}
+
+	if err := WriteFile(ctx, cmdPrefix, fmt.Sprintf("%s/%s", folderPath(folder), spec.ASTAnalyzer.FileName), spec.ASTAnalyzer.Content); err != nil {
+		return nil, err
+	}
+
+	astJobId := fmt.Sprintf("%s-ast", request.JobID.String())
+	cfgPath := fmt.Sprintf("/tmp/config-%s.cfg", astJobId)
+	args := append(append([]string{}, spec.ASTAnalyzer.Flags...), checkerPath(spec.ASTAnalyzer.FileName))
+	config := prepareNsjailConfig(withCheckerFolder(spec.NsjailConfigTemplate, folder), astJobId, request.JobID.String(), args...)
+
+	if err := CreateConfigFile(ctx, cmdPrefix, cfgPath, config); err != nil {
+		return nil, err
+	}
+
+	defer DeleteFile(ctx, cmdPrefix, cfgPath)
+
+	r, err := ExecuteNsjail(ctx, cmdPrefix, cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return request.ASTChecker.Check(ctx, r.Stdout), nil
+}
+
+// runLintChecker runs the driver's linter over the submitted source files inside the sandbox.
+func runLintChecker(
+	ctx context.Context,
+	request models.ExecutionRequest,
+	cmdPrefix string,
+	spec Spec,
+) ([]checkers.CheckerResult, error) {
+	if spec.Linter.Parse == nil {
+		return request.LintChecker.Failed("linting is not supported for this language"), nil
+	}
+
+	config := request.LintChecker.Config
+	if config == "" {
+		config = spec.Linter.DefaultConfig
+	}
+
+	folder := checkerFolder(request)
+	if err := WriteFile(ctx, cmdPrefix, fmt.Sprintf("%s/%s", folderPath(folder), spec.Linter.ConfigFileName), config); err != nil {
+		return nil, err
+	}
+
+	lintJobId := fmt.Sprintf("%s-lint", request.JobID.String())
+	cfgPath := fmt.Sprintf("/tmp/config-%s.cfg", lintJobId)
+	args := append(append([]string{}, spec.Linter.Args...), sourceFiles(request.Source, spec.SourceExtension)...)
+	nsjailConfig := prepareNsjailConfig(withCheckerFolder(spec.NsjailConfigTemplate, folder), lintJobId, request.JobID.String(), args...)
+
+	if err := CreateConfigFile(ctx, cmdPrefix, cfgPath, nsjailConfig); err != nil {
+		return nil, err
+	}
+
+	defer DeleteFile(ctx, cmdPrefix, cfgPath)
+
+	r, err := ExecuteNsjail(ctx, cmdPrefix, cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Linters exit non-zero when they find issues, so only treat it as a failure when nothing was reported.
+	findings := spec.Linter.Parse(r.Stdout)
+	if len(findings) == 0 && r.ExitCode != 0 {
+		return request.LintChecker.Failed(firstLine(r.Stderr)), nil
+	}
+
+	return request.LintChecker.Check(ctx, findings), nil
+}
+
+// runPerformanceChecker runs the driver's harness with its own time limit, since measuring
+// large inputs takes longer than a regular run.
+func runPerformanceChecker(
+	ctx context.Context,
+	request models.ExecutionRequest,
+	cmdPrefix string,
+	spec Spec,
+) (checkers.CheckerResult, error) {
+	if spec.PerformanceHarness.Content == "" {
+		return request.PerformanceChecker.Unsupported(), nil
+	}
+
+	input, err := request.PerformanceChecker.Input(request.EntryPoint)
+	if err != nil {
+		return checkers.CheckerResult{}, err
+	}
+
+	config := withEnvironment(spec.NsjailConfigTemplate, spec, request)
+	generatorFileName := fmt.Sprintf(".perf_generator.%s", spec.SourceExtension)
+	timeLimit := request.PerformanceChecker.TimeLimitSeconds()
+
+	files := map[string]string{
+		performanceInputFileName: input,
+		generatorFileName:        request.PerformanceChecker.Generator,
+	}
+
+	r, err := runHarness(ctx, request, cmdPrefix, config, request.JobID.String(), checkerFolder(request), "perf", spec.PerformanceHarness, timeLimit, files)
+	if err != nil {
+		return checkers.CheckerResult{}, err
+	}
+
+	referenceStdout := ""
+	if request.PerformanceChecker.Reference != "" {
+		referenceFileName := fmt.Sprintf(".perf_reference.%s", spec.SourceExtension)
+		input, err := request.PerformanceChecker.ReferenceInput(checkerPath(referenceFileName))
+		if err != nil {
+			return checkers.CheckerResult{}, err
+		}
+
+		files := map[string]string{
+			performanceInputFileName: input,
+			generatorFileName:        request.PerformanceChecker.Generator,
+			referenceFileName:        request.PerformanceChecker.Reference,
+		}
+
+		reference, err := runReference(ctx, request, cmdPrefix, config, "perf-reference", spec.PerformanceHarness, timeLimit, files)
+		if err != nil {
+			return checkers.CheckerResult{}, err
+		}
+		referenceStdout = reference.Stdout
+	}
+
+	return request.PerformanceChecker.Check(ctx, r.Stdout, referenceStdout), nil
+}
+
+// runDifferentialChecker runs the driver's harness twice: over the reference solution, in a sandbox of its own
+// where it also generates the inputs, and over the submission with those inputs. The outputs are compared here.
+func runDifferentialChecker(
+	ctx context.Context,
+	request models.ExecutionRequest,
+	cmdPrefix string,
+	spec Spec,
+) (checkers.CheckerResult, error) {
+	if spec.DifferentialHarness.Content == "" {
+		return request.DifferentialChecker.Unsupported(), nil
+	}
+
+	config := withEnvironment(spec.NsjailConfigTemplate, spec, request)
+	timeLimit := request.DifferentialChecker.TimeLimitSeconds()
+
+	referenceFileName := fmt.Sprintf(".diff_reference.%s", spec.SourceExtension)
+	input, err := request.DifferentialChecker.ReferenceInput(checkerPath(referenceFileName))
+	if err != nil {
+		return checkers.CheckerResult{}, err
+	}
+
+	files := map[string]string{
+		differentialInputFileName:                               input,
+		fmt.Sprintf(".diff_generator.%s", spec.SourceExtension): request.DifferentialChecker.Generator,
+		referenceFileName:                                       request.DifferentialChecker.Reference,
+	}
+
+	reference, err := runReference(ctx, request, cmdPrefix, config, "diff-reference", spec.DifferentialHarness, timeLimit, files)
+	if err != nil {
+		return checkers.CheckerResult{}, err
+	}
+
+	cases, err := request.DifferentialChecker.ReferenceCases(reference.Stdout)
+	if err != nil {
+		return request.DifferentialChecker.Failed(err), nil
+	}
+
+	input, err = request.DifferentialChecker.Input(runArgs(spec, request), cases)
+	if err != nil {
+		return checkers.CheckerResult{}, err
+	}
+
+	files = map[string]string{
+		differentialInputFileName: input,
+	}
+
+	r, err := runHarness(ctx, request, cmdPrefix, config, request.JobID.String(), checkerFolder(request), "diff", spec.DifferentialHarness, timeLimit, files)
+	if err != nil {
+		return checkers.CheckerResult{}, err
+	}
+
+	return request.DifferentialChecker.Check(ctx, cases, r.Stdout), nil
+}
+
+// runFileSystemChecker runs the driver's inspector over the paths the checker expects,
+// inside the sandbox so that links created by the submission can't point outside of it.
+func runFileSystemChecker(
+	ctx context.Context,
+	request models.ExecutionRequest,
+	cmdPrefix string,
+	spec Spec,
+) ([]checkers.CheckerResult, error) {
+	if spec.FileSystemInspector.Content == "" {
+		return request.FileSystemChecker.Unsupported(), nil
+	}
+
+	files := map[string]string{
+		fileSystemInputFileName: request.FileSystemChecker.Input(),
+	}
+
+	r, err := runHarness(ctx, request, cmdPrefix, spec.NsjailConfigTemplate, request.JobID.String(), checkerFolder(request), "fs", spec.FileSystemInspector, fileSystemTimeLimit, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return request.FileSystemChecker.Check(ctx, r.Stdout), nil
+}
+
+// runServerChecker runs the driver's harness, which starts the submission as a server, waits for it
+// to listen and then runs the exercise's checker script against it. The sandbox gets loopback networking.
+func runServerChecker(
+	ctx context.Context,
+	request models.ExecutionRequest,
+	cmdPrefix string,
+	spec Spec,
+) ([]checkers.CheckerResult, error) {
+	if spec.ServerHarness.Content == "" {
+		return []checkers.CheckerResult{request.ServerChecker.Unsupported()}, nil
+	}
+
+	input, err := request.ServerChecker.Input(runArgs(spec, request))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{
+		serverInputFileName: input,
+		fmt.Sprintf(".server_checker.%s", spec.SourceExtension): request.ServerChecker.Script,
+	}
+	if spec.TestUtilsFile != "" {
+		files["test_utils.py"] = spec.TestUtilsFile
+	}
+
+	config := withLoopback(withEnvironment(spec.NsjailConfigTemplate, spec, request))
+	r, err := runHarness(ctx, request, cmdPrefix, config, request.JobID.String(), checkerFolder(request), "server", spec.ServerHarness, request.ServerChecker.TimeLimitSeconds(), files)
+	if err != nil {
+		return nil, err
+	}
+
+	return request.ServerChecker.Check(ctx, r.Stdout), nil
+}
+
+// runReference runs the harness over the exercise's reference solution in a sandbox of its own, whose folder
+// holds nothing but the files. The submission never runs next to the reference, so it can't read it.
+func runReference(
+	ctx context.Context,
+	request models.ExecutionRequest,
+	cmdPrefix string,
+	nsjailConfigTemplate string,
+	name string,
+	harness Script,
+	timeLimit int,
+	files map[string]string,
+) (models.ExecuteResponse, error) {
+	folder := fmt.Sprintf("%s-%s", request.JobID.String(), name)
+	if err := CreateJobDirectory(ctx, cmdPrefix, folderPath(folder)); err != nil {
+		return models.ExecuteResponse{}, fmt.Errorf("failed to create reference directory: %w", err)
+	}
+
+	defer DeleteJobDirectory(ctx, cmdPrefix, folderPath(folder))
+
+	return runHarness(ctx, request, cmdPrefix, nsjailConfigTemplate, folder, folder, name, harness, timeLimit, files)
+}
+
+// runHarness writes the files and the harness script to folder, mounted at checkerMountPath, and runs
+// the harness in its own sandbox with the given time limit, with workFolder as its working directory.
+func runHarness(
+	ctx context.Context,
+	request models.ExecutionRequest,
+	cmdPrefix string,
+	nsjailConfigTemplate string,
+	workFolder string,
+	folder string,
+	name string,
+	harness Script,
+	timeLimit int,
+	files map[string]string,
+) (models.ExecuteResponse, error) {
+	files[harness.FileName] = harness.Content
+	for fileName, content := range files {
+		if err := WriteFile(ctx, cmdPrefix, fmt.Sprintf("%s/%s", folderPath(folder), fileName), content); err != nil {
+			return models.ExecuteResponse{}, err
+		}
+	}
+
+	harnessJobId := fmt.Sprintf("%s-%s", request.JobID.String(), name)
+	cfgPath := fmt.Sprintf("/tmp/config-%s.cfg", harnessJobId)
+	args := append(append([]string{}, harness.Flags...), checkerPath(harness.FileName))
+	config := withCheckerFolder(withTimeLimit(nsjailConfigTemplate, timeLimit), folder)
+	config = prepareNsjailConfig(config, harnessJobId, workFolder, args...)
+
+	if err := CreateConfigFile(ctx, cmdPrefix, cfgPath, config); err != nil {
+		return models.ExecuteResponse{}, err
+	}
+
+	defer DeleteFile(ctx, cmdPrefix, cfgPath)
+
+	return ExecuteNsjail(ctx, cmdPrefix, cfgPath)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// sourceFiles lists the submitted files with the given extension, relative to the job directory.
+func sourceFiles(entry fs.Entry, extension string) []string {
+	files := make([]string, 0)
+	for _, child := range entry.Children {
+		if len(child.Children) > 0 {
+			for _, file := range sourceFiles(child, extension) {
+				files = append(files, fmt.Sprintf("%s/%s", child.Name, file))
+			}
+		} else if child.Content != "" && strings.HasSuffix(child.Name, "."+extension) {
+			files = append(files, child.Name)
+		}
+	}
+
+	if entry.Content != "" && strings.HasSuffix(entry.Name, "."+extension) {
+		files = append(files, entry.Name)
+	}
+
+	return files
+}
+
+func workPath(fileName string) string {
+	return fmt.Sprintf("/work/%s", fileName)
+}
+
+func checkerPath(fileName string) string {
+	return fmt.Sprintf("%s/%s", checkerMountPath, fileName)
+}
+
+// folderPath is the path of a folder under /jobs, where the job directories live.
+func folderPath(folder string) string {
+	return fmt.Sprintf("/jobs/%s", folder)
+}
+
+// checkerFolder is the folder next to the job directory that holds the checker scripts and their inputs.
+func checkerFolder(request models.ExecutionRequest) string {
+	return fmt.Sprintf("%s-checker", request.JobID.String())
+}
+
+// usesCheckerFolder reports whether a checker of the request runs a script from the checker folder.
+func usesCheckerFolder(request models.ExecutionRequest) bool {
+	return request.FileSystemChecker != nil ||
+		request.ASTChecker != nil ||
+		request.LintChecker != nil ||
+		request.PerformanceChecker != nil ||
+		request.DifferentialChecker != nil ||
+		request.ServerChecker != nil
+}
+
+// limitExceeded reports whether the run was stopped by its time limit or ran out of memory.
+func limitExceeded(r models.ExecuteResponse, timeLimit int) bool {
+	if r.ExitCode == killedExitCode || r.ExitCode == cpuLimitExitCode {
+		return true
+	}
+	if r.Time >= float64(timeLimit) {
+		return true
+	}
+	if r.Error != nil && r.Error.Type == "MemoryError" {
+		return true
+	}
+
+	// The address space limit makes allocations fail rather than killing the run,
+	// so running out of memory shows as the runtime's own error.
+	stderr := strings.ToLower(r.Stderr)
+	return strings.Contains(stderr, "out of memory") || strings.Contains(stderr, "cannot allocate memory")
+}
+
+// parseRuntimeError parses the exception of a failed run and tells the frames
+// in the submitted files apart from the rest.
+func parseRuntimeError(spec Spec, request models.ExecutionRequest, stderr string) *models.RuntimeError {
+	runtimeError := spec.Traceback(stderr)
+	if runtimeError == nil {
+		return nil
+	}
+
+	files := sourceFiles(request.Source, spec.SourceExtension)
+	for i, frame := range runtimeError.Frames {
+		file, ok := strings.CutPrefix(frame.File, workPath(""))
+		if ok && slices.Contains(files, file) {
+			runtimeError.Frames[i].File = file
+			runtimeError.Frames[i].User = true
+		}
+	}
+
+	return runtimeError
+}
+
+// runArgs are the interpreter arguments that start the submission:
+// the entry point unless the exercise sets its own command, followed by the program arguments.
+// Drivers with a runner get the runner first, see withRunner.
+func runArgs(spec Spec, request models.ExecutionRequest) []string {
+	args := []string{workPath(request.EntryPoint)}
+	if request.Run != nil {
+		if len(request.Run.Command) > 0 {
+			args = append([]string{}, request.Run.Command...)
+		}
+		args = append(args, request.Run.Args...)
+	}
+
+	return withRunner(spec, args...)
+}
+
+// withRunner puts the driver's runner, if it has one, in front of the interpreter arguments.
+func withRunner(spec Spec, args ...string) []string {
+	if spec.Runner.Content == "" {
+		return args
+	}
+
+	runner := append(append([]string{}, spec.Runner.Flags...), workPath(spec.Runner.FileName))
+	return append(runner, args...)
+}
+
+// writeRunnerFiles writes the driver's runner and the database of the request to the job directory.
+func writeRunnerFiles(ctx context.Context, cmdPrefix string, spec Spec, request models.ExecutionRequest, jobPath string) error {
+	files := make(map[string]string)
+	if spec.Runner.Content != "" {
+		files[spec.Runner.FileName] = spec.Runner.Content
+	}
+	if request.Database != nil {
+		files[databaseSchemaFileName] = request.Database.Schema
+		files[databaseFixtureFileName] = request.Database.Fixture
+	}
+
+	for fileName, content := range files {
+		if err := WriteFile(ctx, cmdPrefix, fmt.Sprintf("%s/%s", jobPath, fileName), content); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func prepareNsjailConfig(config string, jobId string, jobFolder string, entryPoint string) string {
+func runEnv(request models.ExecutionRequest) map[string]string {
+	if request.Run == nil {
+		return nil
+	}
+	return request.Run.Env
+}
+
+func prepareNsjailConfig(config string, jobId string, jobFolder string, args ...string) string {
 	config = strings.ReplaceAll(config, "{{JOB_ID}}", jobId)
 	config = strings.ReplaceAll(config, "{{JOB_ID_FOLDER}}", jobFolder)
-	config = strings.ReplaceAll(config, "{{ENTRY_POINT}}", entryPoint)
+	config = strings.ReplaceAll(config, "{{ARGS}}", renderArgs(args))
+	config = withEnv(config, nil)
+	config = strings.ReplaceAll(config, "{{MOUNTS}}", "")
+	config = strings.ReplaceAll(config, "{{NO_LOOPBACK}}", "true")
+	return withTimeLimit(config, defaultTimeLimit)
+}
+
+// withEnvironment mounts the package environment of the request, sets the environment of the run
+// and applies the network profile of the run config.
+func withEnvironment(config string, spec Spec, request models.ExecutionRequest) string {
+	env := make(map[string]string)
+	if request.Environment != "" {
+		config = withMount(config, path.Join(spec.Environment.Root, request.Environment), environmentMountPath)
+		env[spec.Environment.Variable] = spec.Environment.Path
+	}
+
+	// Variables set by the exercise itself win over the environment's.
+	for name, value := range runEnv(request) {
+		env[name] = value
+	}
+
+	config = withEnv(config, env)
+	if request.Run != nil && request.Run.Network == models.NetworkLoopback {
+		config = withLoopback(config)
+	}
+
 	return config
 }
+
+// withMount bind mounts src at dst, read-only. prepareNsjailConfig drops the placeholder once the mounts are applied.
+func withMount(config string, src string, dst string) string {
+	mount := fmt.Sprintf(`mount { src: "%s" dst: "%s" is_bind: true rw: false }`, escapeProtoString(src), escapeProtoString(dst))
+	return strings.ReplaceAll(config, "{{MOUNTS}}", mount+"\n{{MOUNTS}}")
+}
+
+// withCheckerFolder mounts the checker folder at checkerMountPath.
+func withCheckerFolder(config string, folder string) string {
+	return withMount(config, folderPath(folder), checkerMountPath)
+}
+
+// withLoopback brings up the loopback interface of the sandbox. The sandbox keeps its own
+// network namespace, so nothing outside of it is reachable.
+// prepareNsjailConfig leaves loopback down when it wasn't applied.
+func withLoopback(config string) string {
+	return strings.ReplaceAll(config, "{{NO_LOOPBACK}}", "false")
+}
+
+// withEnv sets the environment of the run. prepareNsjailConfig leaves it empty when it wasn't applied.
+func withEnv(config string, env map[string]string) string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = fmt.Sprintf(`envar: "%s"`, escapeProtoString(name+"="+env[name]))
+	}
+	return strings.ReplaceAll(config, "{{ENV}}", strings.Join(lines, "\n"))
+}
+
+// withTimeLimit sets the CPU and wall time limits of the run, in seconds.
+// prepareNsjailConfig falls back to defaultTimeLimit when it wasn't applied.
+func withTimeLimit(config string, seconds int) string {
+	return strings.ReplaceAll(config, "{{TIME_LIMIT}}", strconv.Itoa(seconds))
+}
+
+// renderArgs renders the exec_bin arguments.
+func renderArgs(args []string) string {
+	lines := make([]string, len(args))
+	for i, arg := range args {
+		lines[i] = fmt.Sprintf(`  arg: "%s"`, escapeProtoString(arg))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeProtoString escapes s for a protobuf text format string literal.
+func escapeProtoString(s string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return escaper.Replace(s)
+}
@@ -1,9 +1,12 @@
 package drivers
 
 import (
+	"codim/pkg/executors/drivers/bash"
 	"codim/pkg/executors/drivers/models"
 	"codim/pkg/executors/drivers/node"
 	"codim/pkg/executors/drivers/python"
+	"codim/pkg/executors/drivers/sql"
+	"codim/pkg/executors/drivers/typescript"
 	"codim/pkg/utils/logger"
 	"context"
 	"fmt"
@@ -21,6 +24,12 @@ func New(driver, cmdPrefix string, logger *logger.Logger) (Driver, error) {
 		return python.New(cmdPrefix, logger), nil
 	case "node":
 		return node.New(cmdPrefix, logger), nil
+	case "sql":
+		return sql.New(cmdPrefix, logger), nil
+	case "bash":
+		return bash.New(cmdPrefix, logger), nil
+	case "typescript":
+		return typescript.New(cmdPrefix, logger), nil
 	default:
 		return nil, fmt.Errorf("driver %s is invalid", driver)
 	}
@@ -0,0 +1,37 @@
+package models
+
+// RuntimeError is the uncaught exception that ended the submission, parsed from its stderr.
+type RuntimeError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	// Frames run from the outermost call to where the exception was raised.
+	Frames []StackFrame `json:"frames"`
+	// Explanation describes the error in plain language, when it's a common mistake.
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// Explanation is rendered by the classroom from Key, a translation key, with Params interpolated.
+type Explanation struct {
+	Key    string            `json:"key"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+type StackFrame struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Function string `json:"function,omitempty"`
+	// User is set for frames in the submitted files, whose File is then relative to the job directory.
+	// Frames in the interpreter, the standard library or a harness are not.
+	User bool `json:"user"`
+}
+
+// Location is the innermost frame in the submitted files, where the learner should look first.
+func (e *RuntimeError) Location() *StackFrame {
+	for i := len(e.Frames) - 1; i >= 0; i-- {
+		if e.Frames[i].User {
+			return &e.Frames[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CancelExchange is where the API broadcasts job cancellations to every worker.
+const CancelExchange = "codexec.cancel"
+
+// JobEventMessageType tells job events apart from results on the queue they are replied to.
+const JobEventMessageType = "job.event"
+
+type JobStatus string
+
+const (
+	// JobStatusQueued is reported once the broker has accepted the job.
+	JobStatusQueued JobStatus = "queued"
+	// JobStatusStarted is reported when a worker picks the job from the queue.
+	JobStatusStarted JobStatus = "started"
+	// JobStatusFinished is reported when the job has run, right before its result is published.
+	JobStatusFinished JobStatus = "finished"
+)
+
+// JobEvent reports the progress of a job before its result arrives.
+type JobEvent struct {
+	JobID  uuid.UUID `json:"job_id"`
+	Status JobStatus `json:"status"`
+	// Worker names the worker running the job, once started.
+	Worker    string    `json:"worker,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// JobTiming splits the time a learner waited for a result into queueing and execution.
+type JobTiming struct {
+	QueuedAt   time.Time `json:"queued_at,omitzero"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// QueueWait is the time spent in the queue, including earlier attempts, in seconds.
+	QueueWait float64 `json:"queue_wait"`
+	// ExecutionTime is the time the worker spent on the job, sandbox setup included, in seconds.
+	ExecutionTime float64 `json:"execution_time"`
+}
+
+// NewJobTiming computes the timing of a job. queuedAt is unknown for jobs published without a timestamp.
+func NewJobTiming(queuedAt, startedAt, finishedAt time.Time) *JobTiming {
+	timing := &JobTiming{
+		QueuedAt:      queuedAt,
+		StartedAt:     startedAt,
+		FinishedAt:    finishedAt,
+		ExecutionTime: finishedAt.Sub(startedAt).Seconds(),
+	}
+	if !queuedAt.IsZero() && startedAt.After(queuedAt) {
+		timing.QueueWait = startedAt.Sub(queuedAt).Seconds()
+	}
+	return timing
+}
+
+// JobCancellation asks the workers to stop a job, or to skip it if it is still queued.
+type JobCancellation struct {
+	JobID uuid.UUID `json:"job_id"`
+}
@@ -3,16 +3,136 @@ package models
 import (
 	"codim/pkg/executors/checkers"
 	"codim/pkg/fs"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type ExecutionRequest struct {
-	JobID       uuid.UUID             `json:"job_id"`
-	Source      fs.Entry              `json:"src"`
-	EntryPoint  string                `json:"entry_point"`
-	IOChecker   *checkers.IOChecker   `json:"io_data_checker,omitempty"`
-	CodeChecker *checkers.CodeChecker `json:"code_checker,omitempty"`
+	JobID               uuid.UUID                     `json:"job_id"`
+	Source              fs.Entry                      `json:"src"`
+	EntryPoint          string                        `json:"entry_point"`
+	IOChecker           *checkers.IOChecker           `json:"io_data_checker,omitempty"`
+	CodeChecker         *checkers.CodeChecker         `json:"code_checker,omitempty"`
+	ASTChecker          *checkers.ASTChecker          `json:"ast_checker,omitempty"`
+	LintChecker         *checkers.LintChecker         `json:"lint_checker,omitempty"`
+	PerformanceChecker  *checkers.PerformanceChecker  `json:"performance_checker,omitempty"`
+	DifferentialChecker *checkers.DifferentialChecker `json:"differential_checker,omitempty"`
+	ServerChecker       *checkers.ServerChecker       `json:"server_checker,omitempty"`
+	ResultSetChecker    *checkers.ResultSetChecker    `json:"result_set_checker,omitempty"`
+	FileSystemChecker   *checkers.FileSystemChecker   `json:"filesystem_checker,omitempty"`
+	TypeCheckChecker    *checkers.TypeCheckChecker    `json:"typecheck_checker,omitempty"`
+	Run                 *RunConfig                    `json:"run,omitempty"`
+	// Environment names the preinstalled package environment the submission runs with.
+	Environment string `json:"environment,omitempty"`
+	// Database seeds the database SQL submissions run against.
+	Database *Database `json:"database,omitempty"`
+}
+
+// CheckerTimeLimit is the time the sandboxed runs of the checkers with time limits of their own may take
+// together. They run after the submission, so the execution timeout is extended by it.
+func (e *ExecutionRequest) CheckerTimeLimit() time.Duration {
+	seconds := 0
+	if e.PerformanceChecker != nil {
+		seconds += e.PerformanceChecker.TimeLimitSeconds()
+		if e.PerformanceChecker.Reference != "" {
+			seconds += e.PerformanceChecker.TimeLimitSeconds()
+		}
+	}
+	if e.DifferentialChecker != nil {
+		// The reference's run and the submission's.
+		seconds += 2 * e.DifferentialChecker.TimeLimitSeconds()
+	}
+	if e.ServerChecker != nil {
+		seconds += e.ServerChecker.TimeLimitSeconds()
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// Database is built fresh for every run of a SQL submission.
+type Database struct {
+	// Schema creates the tables.
+	Schema string `json:"schema"`
+	// Fixture fills them with the exercise's data.
+	Fixture string `json:"fixture,omitempty"`
+}
+
+// MaxJobPriority is the highest priority of a job, and the x-max-priority of the job queues of workers
+// with priorities enabled.
+const MaxJobPriority = 4
+
+var (
+	envNamePattern         = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	environmentNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+)
+
+// EnvironmentQueue is the queue of the jobs needing a package environment. Only workers that
+// have the environment installed consume it.
+func EnvironmentQueue(queue string, environment string) string {
+	if environment == "" {
+		return queue
+	}
+	return fmt.Sprintf("%s.%s", queue, environment)
+}
+
+// ValidEnvironmentName reports whether name can safely be used as a package environment
+// directory and queue name suffix.
+func ValidEnvironmentName(name string) bool {
+	return len(name) <= 64 && environmentNamePattern.MatchString(name)
+}
+
+// RunConfig changes how the submission is started. The interpreter is always the driver's own,
+// only its arguments and environment are configurable.
+type RunConfig struct {
+	// Command replaces the entry point as the interpreter arguments, e.g. ["-m", "app"].
+	Command []string `json:"command,omitempty"`
+	// Args are passed to the program after the command.
+	Args []string `json:"args,omitempty"`
+	// Env is the program's environment.
+	Env map[string]string `json:"env,omitempty"`
+	// Network opts the program into a network profile. Without one it has no network at all.
+	Network NetworkProfile `json:"network,omitempty"`
+}
+
+type NetworkProfile string
+
+const (
+	// NetworkLoopback only brings up the loopback interface, so the program can talk to itself
+	// but not to anything outside the sandbox.
+	NetworkLoopback NetworkProfile = "loopback"
+)
+
+// Validate rejects what can't be handed to the sandbox safely.
+func (c *RunConfig) Validate() error {
+	for _, arg := range append(append([]string{}, c.Command...), c.Args...) {
+		if strings.ContainsRune(arg, 0) {
+			return fmt.Errorf("argument %q contains a NUL byte", arg)
+		}
+	}
+
+	if c.Network != "" && c.Network != NetworkLoopback {
+		return fmt.Errorf("unknown network profile %q", c.Network)
+	}
+
+	for name, value := range c.Env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+		// The dynamic loader variables would let the exercise change how the interpreter itself is loaded.
+		if strings.HasPrefix(name, "LD_") {
+			return fmt.Errorf("environment variable %s is not allowed", name)
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("environment variable %s contains a NUL byte", name)
+		}
+	}
+
+	return nil
 }
 
 type ExecuteResponse struct {
@@ -24,6 +144,43 @@ type ExecuteResponse struct {
 	Memory         int64                    `json:"memory"`
 	CPU            float64                  `json:"cpu"`
 	CheckerResults []checkers.CheckerResult `json:"checker_results"`
+	// ResultSets are the rows returned by a SQL submission, one set per statement.
+	ResultSets []checkers.ResultSet `json:"result_sets,omitempty"`
+	// Diagnostics are the compiler messages of languages with a compile phase, e.g. type errors.
+	Diagnostics []checkers.Diagnostic `json:"diagnostics,omitempty"`
+	// Error is the uncaught exception that ended the submission, when the driver can parse it.
+	Error *RuntimeError `json:"error,omitempty"`
+	// Failure is set when the job was abandoned without a result, e.g. after too many failed attempts.
+	Failure string `json:"failure,omitempty"`
+	// Timing is how long the job waited in the queue and ran on the worker.
+	Timing *JobTiming `json:"timing,omitempty"`
+	// Cancelled is set when the learner cancelled the job before it finished.
+	Cancelled bool `json:"cancelled,omitempty"`
+	// LimitExceeded is set when the run was stopped by its time limit or ran out of memory.
+	// Whether it does depends on the load of the worker, so the result may differ next time.
+	LimitExceeded bool `json:"limit_exceeded,omitempty"`
+	// SetupTime is how long preparing the sandbox took, in seconds. It is only reported in the worker's metrics.
+	SetupTime float64 `json:"-"`
+}
+
+// CancelledResponse is sent for a job stopped or skipped because it was cancelled.
+func CancelledResponse(jobID uuid.UUID) ExecuteResponse {
+	return ExecuteResponse{
+		JobID:          jobID,
+		ExitCode:       -1,
+		CheckerResults: []checkers.CheckerResult{},
+		Cancelled:      true,
+	}
+}
+
+// FailedResponse is sent for a job the worker gave up on, so the learner isn't left waiting.
+func FailedResponse(jobID uuid.UUID, failure string) ExecuteResponse {
+	return ExecuteResponse{
+		JobID:          jobID,
+		ExitCode:       -1,
+		CheckerResults: []checkers.CheckerResult{},
+		Failure:        failure,
+	}
 }
 
 func (e *ExecuteResponse) Passed() bool {
@@ -32,10 +189,46 @@ func (e *ExecuteResponse) Passed() bool {
 	}
 
 	for _, checkerResult := range e.CheckerResults {
-		if !checkerResult.Success {
+		if !checkerResult.Success && !checkerResult.Optional {
 			return false
 		}
 	}
 
 	return true
 }
+
+// Score is the weighted share of passed checker results, from 0 to 100.
+// Optional results don't count; without any results the exit code decides.
+func (e *ExecuteResponse) Score() int {
+	total, passed := 0.0, 0.0
+	for _, checkerResult := range e.CheckerResults {
+		if checkerResult.Optional {
+			continue
+		}
+
+		weight := checkers.WeightOrDefault(checkerResult.Weight)
+		total += weight
+		if checkerResult.Success {
+			passed += weight
+		}
+	}
+
+	if total == 0 {
+		if e.ExitCode != 0 {
+			return 0
+		}
+		return 100
+	}
+
+	return int(math.Floor(passed / total * 100))
+}
+
+// PassedWith reports whether the submission reached the pass threshold, a score from 0 to 100.
+// A threshold of 100 is the same as Passed.
+func (e *ExecuteResponse) PassedWith(threshold int) bool {
+	if threshold >= 100 {
+		return e.Passed()
+	}
+
+	return e.ExitCode == 0 && e.Score() >= threshold
+}
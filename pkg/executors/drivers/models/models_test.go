@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"codim/pkg/executors/checkers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScore(t *testing.T) {
+	response := ExecuteResponse{CheckerResults: []checkers.CheckerResult{
+		{Success: true, Weight: 2},
+		{Success: false, Weight: 1},
+		{Success: false, Optional: true, Weight: 10},
+	}}
+
+	require.Equal(t, 66, response.Score())
+	require.False(t, response.Passed())
+	require.True(t, response.PassedWith(60))
+	require.False(t, response.PassedWith(70))
+	require.False(t, response.PassedWith(100))
+}
+
+func TestScoreWithoutResults(t *testing.T) {
+	response := ExecuteResponse{CheckerResults: []checkers.CheckerResult{}}
+	require.Equal(t, 100, response.Score())
+	require.True(t, response.PassedWith(100))
+
+	response.ExitCode = 1
+	require.Equal(t, 0, response.Score())
+	require.False(t, response.PassedWith(0))
+}
+
+func TestScoreOnlyOptionalResults(t *testing.T) {
+	response := ExecuteResponse{CheckerResults: []checkers.CheckerResult{
+		{Success: false, Optional: true},
+	}}
+
+	require.Equal(t, 100, response.Score())
+	require.True(t, response.Passed())
+}
+
+func TestCheckerTimeLimit(t *testing.T) {
+	request := ExecutionRequest{}
+	require.Zero(t, request.CheckerTimeLimit())
+
+	request.DifferentialChecker = &checkers.DifferentialChecker{}
+	request.PerformanceChecker = &checkers.PerformanceChecker{TimeLimit: 3, Reference: "def f(n): ..."}
+	request.ServerChecker = &checkers.ServerChecker{TimeLimit: 4}
+
+	// Two differential runs at the default limit, two performance runs and the server run.
+	require.Equal(t, 20*time.Second, request.CheckerTimeLimit())
+}
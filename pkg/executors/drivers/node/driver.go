@@ -1,8 +1,11 @@
 package node
 
 import (
+	"codim/pkg/executors/checkers"
 	"codim/pkg/executors/drivers/cmd"
 	"codim/pkg/executors/drivers/models"
+	"codim/pkg/executors/explain"
+	"codim/pkg/executors/traceback"
 	"codim/pkg/utils/logger"
 	"context"
 )
@@ -20,7 +23,7 @@ clone_newuts: true
 clone_newuser: true
 
 clone_newnet: true
-iface_no_lo: true
+iface_no_lo: {{NO_LOOPBACK}}
 
 cwd: "/work"
 mount_proc: false
@@ -39,6 +42,7 @@ mount { src: "/usr/bin/time" 		dst: "/usr/bin/time" 		is_bind: true rw: false }
 mount { src: "/usr/lib"         	dst: "/usr/lib"         	is_bind: true rw: false }
 mount { src: "/usr/lib/nodejs"  	dst: "/usr/lib/nodejs"  	is_bind: true rw: false }
 mount { src: "/lib"             	dst: "/lib"             	is_bind: true rw: false }
+{{MOUNTS}}
 
 mount { dst: "/tmp" fstype: "tmpfs" rw: true options: "size=128m" }
 
@@ -47,19 +51,486 @@ mount { src: "/dev/null"    dst: "/dev/null"    is_bind: true rw: true }
 mount { src: "/dev/urandom" dst: "/dev/urandom" is_bind: true rw: false }
 
 rlimit_as: 512
-rlimit_cpu: 1
+rlimit_cpu: {{TIME_LIMIT}}
 rlimit_nofile: 64
 rlimit_nproc: 16
-time_limit: 1
+time_limit: {{TIME_LIMIT}}
+
+{{ENV}}
 
 exec_bin {
   path: "/usr/bin/node"
-  arg: 	"/work/{{ENTRY_POINT}}"
+{{ARGS}}
+}
+`
+	testUtilsFile   = ``
+	astAnalyzerFile = `
+const fs = require("fs");
+const acorn = require("internal/deps/acorn/acorn/dist/acorn");
+
+const CONSTRUCTS = {
+	for: ["ForStatement", "ForInStatement", "ForOfStatement"],
+	while: ["WhileStatement", "DoWhileStatement"],
+	loop: ["ForStatement", "ForInStatement", "ForOfStatement", "WhileStatement", "DoWhileStatement"],
+	comprehension: [],
+	if: ["IfStatement", "ConditionalExpression"],
+	function: ["FunctionDeclaration", "FunctionExpression", "ArrowFunctionExpression"],
+	class: ["ClassDeclaration", "ClassExpression"],
+	call: ["CallExpression", "NewExpression"],
+	import: ["ImportDeclaration", "ImportExpression"],
+	try: ["TryStatement"],
+	return: ["ReturnStatement"],
+};
+
+function isRequire(node) {
+	return node.type === "CallExpression" &&
+		node.callee.type === "Identifier" &&
+		node.callee.name === "require" &&
+		node.arguments.length > 0 &&
+		node.arguments[0].type === "Literal";
+}
+
+function names(node, parent) {
+	if (node.id && node.id.type === "Identifier") {
+		return [node.id.name];
+	}
+	if (parent && parent.type === "VariableDeclarator" && parent.init === node && parent.id.type === "Identifier") {
+		return [parent.id.name];
+	}
+	if (isRequire(node)) {
+		return [node.arguments[0].value];
+	}
+	if (node.type === "CallExpression" || node.type === "NewExpression") {
+		if (node.callee.type === "Identifier") {
+			return [node.callee.name];
+		}
+		if (node.callee.type === "MemberExpression" && !node.callee.computed) {
+			return [node.callee.property.name];
+		}
+	}
+	if (node.type === "ImportDeclaration") {
+		return [node.source.value];
+	}
+	return [];
+}
+
+function matches(rule, node, parent) {
+	const types = CONSTRUCTS[rule.construct] || [];
+	if (!types.includes(node.type) && !(rule.construct === "import" && isRequire(node))) {
+		return false;
+	}
+	return !rule.name || names(node, parent).includes(rule.name);
+}
+
+function visit(node, parent, fn) {
+	fn(node, parent);
+	for (const key of Object.keys(node)) {
+		const child = node[key];
+		for (const c of Array.isArray(child) ? child : [child]) {
+			if (c && typeof c.type === "string") {
+				visit(c, node, fn);
+			}
+		}
+	}
+}
+
+function parse(source) {
+	try {
+		return acorn.parse(source, { ecmaVersion: "latest", sourceType: "script", allowReturnOutsideFunction: true, locations: true });
+	} catch (e) {
+		return acorn.parse(source, { ecmaVersion: "latest", sourceType: "module", locations: true });
+	}
+}
+
+function describe(rule) {
+	return rule.name ? rule.construct + " " + rule.name : rule.construct;
+}
+
+function report(success, message, file = "", line = 0) {
+	console.log(JSON.stringify({ is_test: true, success, message, file, line }));
+}
+
+function main() {
+	const spec = JSON.parse(fs.readFileSync("/checker/.ast_rules.json", "utf8"));
+	const rules = spec.rules;
+	const found = rules.map(() => null);
+
+	for (const path of spec.files) {
+		let tree;
+		try {
+			tree = parse(fs.readFileSync(path, "utf8"));
+		} catch (e) {
+			for (const rule of rules) {
+				report(false, "SyntaxError: " + e.message, path, e.loc ? e.loc.line : 0);
+			}
+			return;
+		}
+		visit(tree, null, (node, parent) => {
+			rules.forEach((rule, i) => {
+				if (!matches(rule, node, parent)) {
+					return;
+				}
+				if (found[i] === null || (found[i][0] === path && node.loc.start.line < found[i][1])) {
+					found[i] = [path, node.loc.start.line];
+				}
+			});
+		});
+	}
+
+	rules.forEach((rule, i) => {
+		const match = found[i];
+		if (rule.kind === "forbid") {
+			if (match) {
+				report(false, rule.message || describe(rule) + " is not allowed", match[0], match[1]);
+			} else {
+				report(true, rule.message || "No " + describe(rule) + " used");
+			}
+		} else if (match) {
+			report(true, rule.message || "Found " + describe(rule), match[0], match[1]);
+		} else {
+			report(false, rule.message || "Missing " + describe(rule));
+		}
+	});
 }
+
+main();
+`
+	performanceHarnessFile = `
+const fs = require("fs");
+const os = require("os");
+const path = require("path");
+const v8 = require("v8");
+const { spawnSync } = require("child_process");
+
+// call runs in a child process: it loads the arguments, requires the module and calls the function.
+// Only the CPU time /usr/bin/time reads from the kernel counts, so the code under test can't tamper
+// with the clock, and its output never reaches the harness's own.
+function call() {
+	const fs = require("fs");
+	const path = require("path");
+	const v8 = require("v8");
+	const vm = require("vm");
+
+	// Functions don't have to be exported: fall back to evaluating the file in its own context
+	// and reading the declaration from its top-level scope.
+	function load(file, name) {
+		const exported = require(path.resolve(file));
+		if (typeof exported[name] === "function") {
+			return exported[name];
+		}
+		if (typeof exported === "function" && exported.name === name) {
+			return exported;
+		}
+		const sandbox = { require, console, module: { exports: {} }, exports: {} };
+		const fn = vm.runInNewContext(fs.readFileSync(file, "utf8") + "\n;" + name, sandbox, { filename: file });
+		if (typeof fn !== "function") {
+			throw new Error(name + " is not defined in " + file);
+		}
+		return fn;
+	}
+
+	const args = v8.deserialize(fs.readFileSync(process.argv[1]));
+	if (process.argv.length > 2) {
+		load(process.argv[2], process.argv[3])(...args);
+	}
+}
+
+const CALL = "(" + call.toString() + ")();";
+
+class CallFailed extends Error {}
+
+// errorLine picks the error message out of an uncaught exception, which node follows with its own version.
+function errorLine(s) {
+	const lines = s.trim().split("\n");
+	return lines.find((line) => /^[A-Za-z]*Error\b/.test(line)) || lines[lines.length - 1];
+}
+
+function cpuTime(args, dir) {
+	const timing = path.join(dir, "time");
+	const p = spawnSync("/usr/bin/time", ["-f", "%U %S", "-o", timing, process.execPath, "-e", CALL, ...args], {
+		stdio: ["ignore", "ignore", "pipe"],
+		encoding: "utf8",
+	});
+	if (p.status !== 0) {
+		throw new CallFailed(errorLine(p.stderr || "") || "exited with code " + p.status);
+	}
+	const [user, system] = fs.readFileSync(timing, "utf8").trim().split("\n").pop().split(" ").map(Number);
+	return user + system;
+}
+
+// measure times a call against a child that only loads the arguments, so starting node doesn't count.
+function measure(spec, argsPath, dir) {
+	let called = Infinity;
+	let loaded = Infinity;
+	for (let i = 0; i < spec.repeats; i++) {
+		called = Math.min(called, cpuTime([argsPath, spec.module, spec.function], dir));
+		loaded = Math.min(loaded, cpuTime([argsPath], dir));
+	}
+	return Math.max(called - loaded, 0);
+}
+
+function report(measurements, error = "") {
+	console.log(JSON.stringify({ is_perf: true, measurements, error }));
+}
+
+function main() {
+	const spec = JSON.parse(fs.readFileSync("/checker/.perf_input.json", "utf8"));
+	const measurements = [];
+
+	let generate;
+	try {
+		generate = require("/checker/.perf_generator.js").generate;
+		if (typeof generate !== "function") {
+			throw new Error("generate is not defined in the generator");
+		}
+	} catch (e) {
+		report(measurements, e.name + ": " + e.message);
+		return;
+	}
+
+	const dir = fs.mkdtempSync(path.join(os.tmpdir(), "perf-"));
+	const argsPath = path.join(dir, "args");
+	const started = Date.now();
+	for (const n of spec.sizes) {
+		if ((Date.now() - started) / 1000 > spec.budget) {
+			break;
+		}
+		let measurement;
+		try {
+			fs.writeFileSync(argsPath, v8.serialize(generate(n)));
+			measurement = { size: n, time: measure(spec, argsPath, dir) };
+		} catch (e) {
+			report(measurements, e instanceof CallFailed ? e.message : e.name + ": " + e.message);
+			return;
+		}
+		measurements.push(measurement);
+		// Report as we go so a run killed by the time limit still shows its curve.
+		report(measurements);
+	}
+}
+
+main();
+`
+	differentialHarnessFile = `
+const fs = require("fs");
+const { spawnSync } = require("child_process");
+
+// mulberry32, so generators get the same inputs for the same seed.
+function seededRandom(seed) {
+	let a = seed >>> 0;
+	return () => {
+		a = (a + 0x6d2b79f5) >>> 0;
+		let t = a;
+		t = Math.imul(t ^ (t >>> 15), t | 1);
+		t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+		return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+	};
+}
+
+function run(command, stdin, timeout) {
+	const p = spawnSync(process.execPath, command, { input: stdin, encoding: "utf8", timeout: timeout * 1000 });
+	if (p.error && p.error.code === "ETIMEDOUT") {
+		return ["", "Timed out"];
+	}
+	const stderr = (p.stderr || "").trim().split("\n");
+	const error = p.status !== 0 ? stderr[stderr.length - 1] || "Exited with code " + p.status : "";
+	return [(p.stdout || "").trim(), error];
+}
+
+function report(result) {
+	console.log(JSON.stringify({ is_diff: true, ...result }));
+}
+
+class GeneratorFailed extends Error {}
+
+function* inputs(spec) {
+	if (!spec.generate) {
+		yield* spec.inputs || [];
+		return;
+	}
+	let generate;
+	try {
+		generate = require("/checker/.diff_generator.js").generate;
+	} catch (e) {
+		throw new GeneratorFailed(e.name + ": " + e.message);
+	}
+	for (let i = 0; i < spec.cases; i++) {
+		let stdin;
+		try {
+			stdin = String(generate(seededRandom(spec.seed + i)));
+		} catch (e) {
+			throw new GeneratorFailed(e.name + ": " + e.message);
+		}
+		yield stdin;
+	}
+}
+
+function main() {
+	const spec = JSON.parse(fs.readFileSync("/checker/.diff_input.json", "utf8"));
+
+	const started = Date.now();
+	try {
+		for (const stdin of inputs(spec)) {
+			if ((Date.now() - started) / 1000 > spec.budget) {
+				break;
+			}
+			const [output, error] = run(spec.command, stdin, spec.case_timeout);
+			report({ input: stdin, output, error });
+		}
+	} catch (e) {
+		if (!(e instanceof GeneratorFailed)) {
+			throw e;
+		}
+		report({ aborted: true, error: "generator failed: " + e.message });
+	}
+}
+
+main();
+`
+	serverHarnessFile = `
+const fs = require("fs");
+const net = require("net");
+const { spawn, spawnSync } = require("child_process");
+
+function report(result) {
+	console.log(JSON.stringify({ is_server: true, ...result }));
+}
+
+// errorLine picks the error message out of an uncaught exception, which node follows with its own version.
+function errorLine(s) {
+	const lines = s.trim().split("\n");
+	return lines.find((line) => /^[A-Za-z]*Error\b/.test(line)) || lines[lines.length - 1];
+}
+
+function canConnect(port) {
+	return new Promise((resolve) => {
+		const socket = net.connect({ host: "127.0.0.1", port }, () => {
+			socket.destroy();
+			resolve(true);
+		});
+		socket.on("error", () => resolve(false));
+	});
+}
+
+function sleep(ms) {
+	return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+async function main() {
+	const spec = JSON.parse(fs.readFileSync("/checker/.server_input.json", "utf8"));
+
+	const started = Date.now();
+	let stderr = "";
+	let exitCode = null;
+	// The server's stdout is dropped, so a chatty server can't fill a pipe and block.
+	const server = spawn(process.execPath, spec.command, { stdio: ["ignore", "ignore", "pipe"] });
+	server.stderr.on("data", (data) => {
+		stderr += data;
+	});
+	server.on("exit", (code) => {
+		exitCode = code === null ? -1 : code;
+	});
+
+	try {
+		let ready = false;
+		while (exitCode === null && Date.now() - started < spec.startup_timeout * 1000) {
+			if (await canConnect(spec.port)) {
+				ready = true;
+				break;
+			}
+			await sleep(50);
+		}
+		if (!ready) {
+			if (exitCode !== null) {
+				report({ error: "server exited: " + (errorLine(stderr) || "exit code " + exitCode) });
+			} else {
+				report({ error: "server is not listening on port " + spec.port });
+			}
+			return;
+		}
+
+		// The server runs in its own process, so blocking the harness while the checker runs is fine.
+		const timeout = Math.max(spec.budget * 1000 - (Date.now() - started), 100);
+		const p = spawnSync(process.execPath, ["/checker/.server_checker.js"], { encoding: "utf8", timeout });
+		process.stdout.write(p.stdout || "");
+		if (p.error && p.error.code === "ETIMEDOUT") {
+			report({ error: "checker timed out" });
+		} else if (p.status !== 0) {
+			report({ error: "checker failed: " + (errorLine(p.stderr || "") || "exit code " + p.status) });
+		}
+	} finally {
+		server.kill("SIGKILL");
+	}
+}
+
+main();
+`
+	lintConfigFile = `module.exports = [
+	{
+		languageOptions: {
+			ecmaVersion: "latest",
+			sourceType: "commonjs",
+			globals: {
+				console: "readonly",
+				require: "readonly",
+				module: "writable",
+				exports: "writable",
+				process: "readonly",
+			},
+		},
+		rules: {
+			"no-undef": "error",
+			"no-unused-vars": "warn",
+			"no-var": "warn",
+			"prefer-const": "warn",
+			"eqeqeq": "warn",
+		},
+	},
+];
 `
-	testUtilsFile = ``
 )
 
+var spec = cmd.Spec{
+	NsjailConfigTemplate: nsjailConfigTemplate,
+	Traceback:            traceback.Node,
+	Explanations:         explain.Node,
+	TestUtilsFile:        testUtilsFile,
+	SourceExtension:      "js",
+	ASTAnalyzer: cmd.Script{
+		FileName: ".ast_analyzer.js",
+		Content:  astAnalyzerFile,
+		// acorn ships with node but is only reachable through its internal modules.
+		Flags: []string{"--expose-internals"},
+	},
+	Linter: cmd.Linter{
+		Args: []string{
+			"/usr/lib/node_modules/eslint/bin/eslint.js",
+			"--config", "/checker/.eslint.config.js",
+			"--format", "json",
+		},
+		ConfigFileName: ".eslint.config.js",
+		DefaultConfig:  lintConfigFile,
+		Parse:          checkers.ParseESLintOutput,
+	},
+	PerformanceHarness: cmd.Script{
+		FileName: ".perf_harness.js",
+		Content:  performanceHarnessFile,
+	},
+	DifferentialHarness: cmd.Script{
+		FileName: ".diff_harness.js",
+		Content:  differentialHarnessFile,
+	},
+	ServerHarness: cmd.Script{
+		FileName: ".server_harness.js",
+		Content:  serverHarnessFile,
+	},
+	Environment: cmd.Environment{
+		Root:     "/opt/environments/node",
+		Variable: "NODE_PATH",
+		Path:     "/env/node_modules",
+	},
+}
+
 type Driver struct {
 	logger    *logger.Logger
 	cmdPrefix string
@@ -76,8 +547,7 @@ func (d *Driver) Execute(ctx context.Context, executionRequest models.ExecutionR
 	return cmd.Execute(
 		ctx,
 		d.cmdPrefix,
-		nsjailConfigTemplate,
-		testUtilsFile,
+		spec,
 		executionRequest,
 	)
 }
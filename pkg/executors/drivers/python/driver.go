@@ -1,8 +1,11 @@
 package python
 
 import (
+	"codim/pkg/executors/checkers"
 	"codim/pkg/executors/drivers/cmd"
 	"codim/pkg/executors/drivers/models"
+	"codim/pkg/executors/explain"
+	"codim/pkg/executors/traceback"
 	"codim/pkg/utils/logger"
 	"context"
 )
@@ -20,7 +23,7 @@ clone_newuts: true
 clone_newuser: true
 
 clone_newnet: true
-iface_no_lo: true
+iface_no_lo: {{NO_LOOPBACK}}
 
 cwd: "/work"
 mount_proc: false
@@ -38,6 +41,7 @@ mount { src: "/usr/bin/python3" dst: "/usr/bin/python3" is_bind: true rw: false
 mount { src: "/usr/bin/time" 	dst: "/usr/bin/time" 	is_bind: true rw: false }
 mount { src: "/usr/lib"         dst: "/usr/lib"         is_bind: true rw: false }
 mount { src: "/lib"             dst: "/lib"             is_bind: true rw: false }
+{{MOUNTS}}
 
 mount { dst: "/tmp" fstype: "tmpfs" rw: true options: "size=128m" }
 
@@ -46,42 +50,394 @@ mount { src: "/dev/null"    dst: "/dev/null"    is_bind: true rw: true }
 mount { src: "/dev/urandom" dst: "/dev/urandom" is_bind: true rw: false }
 
 rlimit_as: 512
-rlimit_cpu: 1
+rlimit_cpu: {{TIME_LIMIT}}
 rlimit_nofile: 64
 rlimit_nproc: 16
-time_limit: 1
+time_limit: {{TIME_LIMIT}}
+
+{{ENV}}
 
 exec_bin {
   path: "/usr/bin/python3"
-  arg: "/work/{{ENTRY_POINT}}"
+{{ARGS}}
 }
 `
 	testUtilsFile = `
 import json
 
 class TestResult:
-	def __init__(self, success, message):
+	def __init__(self, success, message, weight=1):
 		self.success = success
 		self.message = message
+		self.weight = weight
 
 	def __str__(self):
 		return json.dumps({
 			"is_test": True,
 			"success": self.success,
 			"message": self.message,
+			"weight": self.weight,
 		})
 
 class TestUtils:
 	@staticmethod
-	def success(message):
-		print(TestResult(True, message))
+	def success(message, weight=1):
+		print(TestResult(True, message, weight))
 
 	@staticmethod
-	def failure(message):
-		print(TestResult(False, message))
+	def failure(message, weight=1):
+		print(TestResult(False, message, weight))
 	`
+	astAnalyzerFile = `
+import ast
+import json
+
+CONSTRUCTS = {
+	"for": (ast.For, ast.AsyncFor),
+	"while": (ast.While,),
+	"loop": (ast.For, ast.AsyncFor, ast.While, ast.ListComp, ast.SetComp, ast.DictComp, ast.GeneratorExp),
+	"comprehension": (ast.ListComp, ast.SetComp, ast.DictComp, ast.GeneratorExp),
+	"if": (ast.If, ast.IfExp),
+	"function": (ast.FunctionDef, ast.AsyncFunctionDef, ast.Lambda),
+	"class": (ast.ClassDef,),
+	"call": (ast.Call,),
+	"import": (ast.Import, ast.ImportFrom),
+	"try": (ast.Try,),
+	"return": (ast.Return,),
+}
+
+def names(node):
+	if isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+		return [node.name]
+	if isinstance(node, ast.Call):
+		if isinstance(node.func, ast.Name):
+			return [node.func.id]
+		if isinstance(node.func, ast.Attribute):
+			return [node.func.attr]
+	if isinstance(node, ast.Import):
+		return [alias.name.split(".")[0] for alias in node.names]
+	if isinstance(node, ast.ImportFrom):
+		return [(node.module or "").split(".")[0]] + [alias.name for alias in node.names]
+	return []
+
+def matches(rule, node):
+	if not isinstance(node, CONSTRUCTS.get(rule["construct"], ())):
+		return False
+	return not rule.get("name") or rule["name"] in names(node)
+
+def describe(rule):
+	if rule.get("name"):
+		return "%s %s" % (rule["construct"], rule["name"])
+	return rule["construct"]
+
+def report(success, message, file="", line=0):
+	print(json.dumps({
+		"is_test": True,
+		"success": success,
+		"message": message,
+		"file": file,
+		"line": line,
+	}))
+
+def main():
+	with open("/checker/.ast_rules.json") as f:
+		spec = json.load(f)
+
+	rules = spec["rules"]
+	found = [None] * len(rules)
+	for path in spec["files"]:
+		with open(path) as f:
+			source = f.read()
+		try:
+			tree = ast.parse(source, filename=path)
+		except SyntaxError as e:
+			for rule in rules:
+				report(False, "SyntaxError: %s" % e.msg, path, e.lineno or 0)
+			return
+		for node in ast.walk(tree):
+			for i, rule in enumerate(rules):
+				if not matches(rule, node):
+					continue
+				if found[i] is None or (found[i][0] == path and node.lineno < found[i][1]):
+					found[i] = (path, node.lineno)
+
+	for rule, match in zip(rules, found):
+		if rule["kind"] == "forbid":
+			if match:
+				report(False, rule.get("message") or "%s is not allowed" % describe(rule), match[0], match[1])
+			else:
+				report(True, rule.get("message") or "No %s used" % describe(rule))
+		elif match:
+			report(True, rule.get("message") or "Found %s" % describe(rule), match[0], match[1])
+		else:
+			report(False, rule.get("message") or "Missing %s" % describe(rule))
+
+main()
+`
+	performanceHarnessFile = `
+import importlib.util
+import json
+import os
+import pickle
+import subprocess
+import sys
+import tempfile
+import time
+
+# The code under test runs in a child process: it loads the arguments, imports the module and calls the
+# function. Only the CPU time the kernel accounted to the child counts, so the code can't tamper with the
+# clock, and its output never reaches the harness's own.
+CALL = """
+import importlib.util, pickle, sys
+with open(sys.argv[1], "rb") as f:
+	args = pickle.load(f)
+if len(sys.argv) > 2:
+	spec = importlib.util.spec_from_file_location("perf_module", sys.argv[2])
+	module = importlib.util.module_from_spec(spec)
+	spec.loader.exec_module(module)
+	getattr(module, sys.argv[3])(*args)
+"""
+
+class CallFailed(Exception):
+	pass
+
+def load(path, name):
+	spec = importlib.util.spec_from_file_location(name, path)
+	module = importlib.util.module_from_spec(spec)
+	spec.loader.exec_module(module)
+	return module
+
+def cpu_time(argv):
+	with tempfile.TemporaryFile() as stderr:
+		p = subprocess.Popen(argv, stdin=subprocess.DEVNULL, stdout=subprocess.DEVNULL, stderr=stderr)
+		_, status, usage = os.wait4(p.pid, 0)
+		p.returncode = os.waitstatus_to_exitcode(status)
+		if p.returncode != 0:
+			stderr.seek(0)
+			lines = stderr.read().decode(errors="replace").strip().splitlines()
+			raise CallFailed(lines[-1] if lines else "exited with code %d" % p.returncode)
+	return usage.ru_utime + usage.ru_stime
+
+# measure times a call against a child that only loads the arguments, so starting the interpreter doesn't count.
+def measure(spec, args_path):
+	call = [sys.executable, "-c", CALL, args_path, spec["module"], spec["function"]]
+	baseline = [sys.executable, "-c", CALL, args_path]
+	called = min(cpu_time(call) for _ in range(spec["repeats"]))
+	loaded = min(cpu_time(baseline) for _ in range(spec["repeats"]))
+	return max(called - loaded, 0)
+
+def report(measurements, error=""):
+	print(json.dumps({"is_perf": True, "measurements": measurements, "error": error}), flush=True)
+
+def main():
+	with open("/checker/.perf_input.json") as f:
+		spec = json.load(f)
+
+	measurements = []
+	try:
+		generate = load("/checker/.perf_generator.py", "perf_generator").generate
+	except Exception as e:
+		report(measurements, "%s: %s" % (type(e).__name__, e))
+		return
+
+	started = time.monotonic()
+	with tempfile.TemporaryDirectory() as tmp:
+		args_path = os.path.join(tmp, "args.pickle")
+		for n in spec["sizes"]:
+			if time.monotonic() - started > spec["budget"]:
+				break
+			try:
+				with open(args_path, "wb") as f:
+					pickle.dump(generate(n), f)
+				measurement = {"size": n, "time": measure(spec, args_path)}
+			except Exception as e:
+				report(measurements, str(e) if isinstance(e, CallFailed) else "%s: %s" % (type(e).__name__, e))
+				return
+			measurements.append(measurement)
+			# Report as we go so a run killed by the time limit still shows its curve.
+			report(measurements)
+
+main()
+`
+	differentialHarnessFile = `
+import importlib.util
+import json
+import random
+import subprocess
+import sys
+import time
+
+def load(path, name):
+	spec = importlib.util.spec_from_file_location(name, path)
+	module = importlib.util.module_from_spec(spec)
+	spec.loader.exec_module(module)
+	return module
+
+def run(command, stdin, timeout):
+	try:
+		p = subprocess.run(
+			[sys.executable] + command,
+			input=stdin,
+			capture_output=True,
+			text=True,
+			timeout=timeout,
+		)
+	except subprocess.TimeoutExpired:
+		return "", "Timed out"
+	error = p.stderr.strip().splitlines()[-1] if p.returncode != 0 and p.stderr.strip() else ""
+	return p.stdout.strip(), error
+
+def report(**result):
+	print(json.dumps(dict(is_diff=True, **result)))
+
+class GeneratorFailed(Exception):
+	pass
+
+def inputs(spec):
+	if not spec["generate"]:
+		yield from spec.get("inputs", [])
+		return
+	try:
+		generate = load("/checker/.diff_generator.py", "diff_generator").generate
+		for i in range(spec["cases"]):
+			yield str(generate(random.Random(spec["seed"] + i)))
+	except Exception as e:
+		raise GeneratorFailed("%s: %s" % (type(e).__name__, e))
+
+def main():
+	with open("/checker/.diff_input.json") as f:
+		spec = json.load(f)
+
+	started = time.monotonic()
+	try:
+		for stdin in inputs(spec):
+			if time.monotonic() - started > spec["budget"]:
+				break
+			output, error = run(spec["command"], stdin, spec["case_timeout"])
+			report(input=stdin, output=output, error=error)
+	except GeneratorFailed as e:
+		report(aborted=True, error="generator failed: %s" % e)
+
+main()
+`
+	serverHarnessFile = `
+import json
+import socket
+import subprocess
+import sys
+import tempfile
+import time
+
+def report(**result):
+	print(json.dumps(dict(is_server=True, **result)))
+
+def last_line(f):
+	f.seek(0)
+	lines = f.read().decode(errors="replace").strip().splitlines()
+	return lines[-1] if lines else ""
+
+def wait_for_port(server, port, timeout):
+	deadline = time.monotonic() + timeout
+	while time.monotonic() < deadline:
+		if server.poll() is not None:
+			return False
+		try:
+			with socket.create_connection(("127.0.0.1", port), timeout=0.1):
+				return True
+		except OSError:
+			time.sleep(0.05)
+	return False
+
+def stop(server):
+	server.terminate()
+	try:
+		server.wait(timeout=0.5)
+	except subprocess.TimeoutExpired:
+		server.kill()
+		server.wait()
+
+def main():
+	with open("/checker/.server_input.json") as f:
+		spec = json.load(f)
+
+	# The server's stdout is dropped and its stderr goes to a file, so a chatty server can't fill a pipe and block.
+	with tempfile.TemporaryFile() as stderr:
+		started = time.monotonic()
+		server = subprocess.Popen([sys.executable] + spec["command"], stdout=subprocess.DEVNULL, stderr=stderr)
+		try:
+			if not wait_for_port(server, spec["port"], spec["startup_timeout"]):
+				if server.poll() is not None:
+					report(error="server exited: %s" % (last_line(stderr) or "exit code %d" % server.returncode))
+				else:
+					report(error="server is not listening on port %d" % spec["port"])
+				return
+
+			timeout = max(spec["budget"] - (time.monotonic() - started), 0.1)
+			try:
+				p = subprocess.run([sys.executable, "/checker/.server_checker.py"], capture_output=True, text=True, timeout=timeout)
+			except subprocess.TimeoutExpired as e:
+				print(e.stdout.decode(errors="replace") if isinstance(e.stdout, bytes) else e.stdout or "")
+				report(error="checker timed out")
+				return
+			print(p.stdout)
+			if p.returncode != 0:
+				lines = p.stderr.strip().splitlines()
+				report(error="checker failed: %s" % (lines[-1] if lines else "exit code %d" % p.returncode))
+		finally:
+			stop(server)
+
+main()
+`
+	lintConfigFile = `[flake8]
+max-line-length = 100
+`
 )
 
+var spec = cmd.Spec{
+	NsjailConfigTemplate: nsjailConfigTemplate,
+	Traceback:            traceback.Python,
+	Explanations:         explain.Python,
+	TestUtilsFile:        testUtilsFile,
+	SourceExtension:      "py",
+	ASTAnalyzer: cmd.Script{
+		FileName: ".ast_analyzer.py",
+		Content:  astAnalyzerFile,
+		// Isolated mode keeps modules the submission left in the working directory from shadowing the standard library.
+		Flags: []string{"-I"},
+	},
+	Linter: cmd.Linter{
+		// Isolated mode keeps the working directory, where the submission could leave modules
+		// shadowing flake8 or the standard library, off the module search path.
+		Args: []string{
+			"-I",
+			"-m", "flake8",
+			"--jobs=1",
+			"--config", "/checker/.flake8",
+			"--format=%(path)s:%(row)d:%(col)d:%(code)s:%(text)s",
+		},
+		ConfigFileName: ".flake8",
+		DefaultConfig:  lintConfigFile,
+		Parse:          checkers.ParseFlake8Output,
+	},
+	PerformanceHarness: cmd.Script{
+		FileName: ".perf_harness.py",
+		Content:  performanceHarnessFile,
+	},
+	DifferentialHarness: cmd.Script{
+		FileName: ".diff_harness.py",
+		Content:  differentialHarnessFile,
+	},
+	ServerHarness: cmd.Script{
+		FileName: ".server_harness.py",
+		Content:  serverHarnessFile,
+	},
+	Environment: cmd.Environment{
+		Root:     "/opt/environments/python",
+		Variable: "PYTHONPATH",
+		Path:     "/env",
+	},
+}
+
 type Driver struct {
 	logger    *logger.Logger
 	cmdPrefix string
@@ -98,8 +454,7 @@ func (d *Driver) Execute(ctx context.Context, executionRequest models.ExecutionR
 	return cmd.Execute(
 		ctx,
 		d.cmdPrefix,
-		nsjailConfigTemplate,
-		testUtilsFile,
+		spec,
 		executionRequest,
 	)
 }
@@ -0,0 +1,219 @@
+package sql
+
+import (
+	"codim/pkg/executors/checkers"
+	"codim/pkg/executors/drivers/cmd"
+	"codim/pkg/executors/drivers/models"
+	"codim/pkg/utils/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	nsjailConfigTemplate = `name: "JOB-{{JOB_ID}}"
+mode: ONCE
+hostname: "JOB-{{JOB_ID}}"
+
+clone_newns: true
+
+clone_newpid: true
+clone_newipc: true
+clone_newuts: true
+clone_newuser: true
+
+clone_newnet: true
+iface_no_lo: {{NO_LOOPBACK}}
+
+cwd: "/work"
+mount_proc: false
+
+mount {
+  src: "/opt/nsjail/rootfs"
+  dst: "/"
+  is_bind: true
+  rw: false
+}
+
+mount { src: "/jobs/{{JOB_ID_FOLDER}}" dst: "/work" is_bind: true rw: true }
+
+mount { src: "/usr/bin/python3" dst: "/usr/bin/python3" is_bind: true rw: false }
+mount { src: "/usr/bin/time" 	dst: "/usr/bin/time" 	is_bind: true rw: false }
+mount { src: "/usr/lib"         dst: "/usr/lib"         is_bind: true rw: false }
+mount { src: "/lib"             dst: "/lib"             is_bind: true rw: false }
+{{MOUNTS}}
+
+mount { dst: "/tmp" fstype: "tmpfs" rw: true options: "size=128m" }
+
+mount { dst: "/dev" fstype: "tmpfs" rw: false }
+mount { src: "/dev/null"    dst: "/dev/null"    is_bind: true rw: true }
+mount { src: "/dev/urandom" dst: "/dev/urandom" is_bind: true rw: false }
+
+rlimit_as: 512
+rlimit_cpu: {{TIME_LIMIT}}
+rlimit_nofile: 64
+rlimit_nproc: 16
+time_limit: {{TIME_LIMIT}}
+
+{{ENV}}
+
+exec_bin {
+  path: "/usr/bin/python3"
+{{ARGS}}
+}
+`
+	runnerFile = `
+import json
+import os
+import sqlite3
+import sys
+
+MAX_ROWS = 1000
+
+def statements(script):
+	parts = script.split(";")
+	statement = ""
+	for i, part in enumerate(parts):
+		statement += part
+		if i < len(parts) - 1:
+			statement += ";"
+		# A semicolon inside a string literal or a trigger body doesn't end the statement.
+		if sqlite3.complete_statement(statement) or i == len(parts) - 1:
+			if statement.strip(" \t\r\n;"):
+				yield statement
+			statement = ""
+
+def value(v):
+	if isinstance(v, bytes):
+		return v.hex()
+	return v
+
+def fail(message):
+	print(message, file=sys.stderr)
+	sys.exit(1)
+
+def run_script(db, path, name):
+	if not os.path.exists(path):
+		return
+	with open(path) as f:
+		script = f.read()
+	try:
+		db.executescript(script)
+	except sqlite3.Error as e:
+		fail("%s failed: %s" % (name, e))
+
+def main():
+	db = sqlite3.connect(":memory:")
+	run_script(db, ".schema.sql", "schema")
+	run_script(db, ".fixture.sql", "fixture")
+
+	with open(sys.argv[1]) as f:
+		script = f.read()
+
+	for statement in statements(script):
+		try:
+			cursor = db.execute(statement)
+		except sqlite3.Error as e:
+			fail("Error: %s" % e)
+		if cursor.description is None:
+			continue
+		rows = cursor.fetchmany(MAX_ROWS + 1)
+		print(json.dumps({
+			"is_result_set": True,
+			"columns": [d[0] for d in cursor.description],
+			"rows": [[value(v) for v in row] for row in rows[:MAX_ROWS]],
+			"truncated": len(rows) > MAX_ROWS,
+		}))
+
+main()
+`
+)
+
+var spec = cmd.Spec{
+	NsjailConfigTemplate: nsjailConfigTemplate,
+	SourceExtension:      "sql",
+	Runner: cmd.Script{
+		FileName: ".sql_runner.py",
+		Content:  runnerFile,
+	},
+	Output: parseResultSets,
+}
+
+type Driver struct {
+	logger    *logger.Logger
+	cmdPrefix string
+}
+
+func New(cmdPrefix string, logger *logger.Logger) *Driver {
+	return &Driver{
+		logger:    logger,
+		cmdPrefix: cmdPrefix,
+	}
+}
+
+func (d *Driver) Execute(ctx context.Context, executionRequest models.ExecutionRequest) (models.ExecuteResponse, error) {
+	return cmd.Execute(
+		ctx,
+		d.cmdPrefix,
+		spec,
+		executionRequest,
+	)
+}
+
+func (d *Driver) SetCmdPrefix(prefix string) error {
+	d.cmdPrefix = prefix
+	return nil
+}
+
+func (d *Driver) CmdPrefix() string {
+	return d.cmdPrefix
+}
+
+type resultSetLine struct {
+	IsResultSet bool `json:"is_result_set"`
+	checkers.ResultSet
+}
+
+// parseResultSets moves the result sets reported by the runner out of stdout
+// and renders them as plain text tables in their place.
+func parseResultSets(response *models.ExecuteResponse) {
+	var stdout strings.Builder
+	for _, line := range strings.Split(response.Stdout, "\n") {
+		var r resultSetLine
+		if err := json.Unmarshal([]byte(line), &r); err != nil || !r.IsResultSet {
+			if line != "" {
+				stdout.WriteString(line + "\n")
+			}
+			continue
+		}
+
+		response.ResultSets = append(response.ResultSets, r.ResultSet)
+		stdout.WriteString(renderResultSet(r.ResultSet))
+	}
+
+	response.Stdout = stdout.String()
+}
+
+func renderResultSet(rs checkers.ResultSet) string {
+	var b strings.Builder
+	b.WriteString(strings.Join(rs.Columns, " | ") + "\n")
+	for _, row := range rs.Rows {
+		values := make([]string, len(row))
+		for i, v := range row {
+			if v == nil {
+				values[i] = "NULL"
+			} else {
+				values[i] = fmt.Sprint(v)
+			}
+		}
+		b.WriteString(strings.Join(values, " | ") + "\n")
+	}
+
+	if rs.Truncated {
+		b.WriteString("...\n")
+	}
+	b.WriteString(fmt.Sprintf("(%d rows)\n\n", len(rs.Rows)))
+
+	return b.String()
+}
@@ -0,0 +1,203 @@
+package typescript
+
+import (
+	"codim/pkg/executors/checkers"
+	"codim/pkg/executors/drivers/cmd"
+	"codim/pkg/executors/drivers/models"
+	"codim/pkg/executors/explain"
+	"codim/pkg/executors/traceback"
+	"codim/pkg/utils/logger"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	nsjailConfigTemplate = `name: "JOB-{{JOB_ID}}"
+mode: ONCE
+hostname: "JOB-{{JOB_ID}}"
+
+clone_newns: true
+
+clone_newpid: true
+clone_newipc: true
+clone_newuts: true
+clone_newuser: true
+
+clone_newnet: true
+iface_no_lo: {{NO_LOOPBACK}}
+
+cwd: "/work"
+mount_proc: false
+
+mount {
+  src: "/opt/nsjail/rootfs"
+  dst: "/"
+  is_bind: true
+  rw: false
+}
+
+mount { src: "/jobs/{{JOB_ID_FOLDER}}" dst: "/work" is_bind: true rw: true }
+
+mount { src: "/usr/bin/node" 		dst: "/usr/bin/node" 		is_bind: true rw: false }
+mount { src: "/usr/bin/time" 		dst: "/usr/bin/time" 		is_bind: true rw: false }
+mount { src: "/usr/lib"         	dst: "/usr/lib"         	is_bind: true rw: false }
+mount { src: "/usr/lib/nodejs"  	dst: "/usr/lib/nodejs"  	is_bind: true rw: false }
+mount { src: "/lib"             	dst: "/lib"             	is_bind: true rw: false }
+{{MOUNTS}}
+
+mount { dst: "/tmp" fstype: "tmpfs" rw: true options: "size=128m" }
+
+mount { dst: "/dev" fstype: "tmpfs" rw: false }
+mount { src: "/dev/null"    dst: "/dev/null"    is_bind: true rw: true }
+mount { src: "/dev/urandom" dst: "/dev/urandom" is_bind: true rw: false }
+
+rlimit_as: 512
+rlimit_cpu: {{TIME_LIMIT}}
+rlimit_nofile: 64
+rlimit_nproc: 16
+time_limit: {{TIME_LIMIT}}
+
+{{ENV}}
+
+exec_bin {
+  path: "/usr/bin/node"
+{{ARGS}}
+}
+`
+	runnerFile = `
+const fs = require("fs");
+const path = require("path");
+const ts = require("/usr/lib/node_modules/typescript");
+
+const WORK_DIR = "/work";
+const OUT_DIR = "/tmp/ts-out";
+
+const SEVERITIES = {
+	[ts.DiagnosticCategory.Error]: "error",
+	[ts.DiagnosticCategory.Warning]: "warning",
+};
+
+function sourceFiles(dir) {
+	return fs.readdirSync(dir, { withFileTypes: true }).flatMap((entry) => {
+		const file = path.join(dir, entry.name);
+		if (entry.isDirectory()) {
+			return entry.name === "node_modules" ? [] : sourceFiles(file);
+		}
+		return /\.tsx?$/.test(entry.name) ? [file] : [];
+	});
+}
+
+function report(diagnostic) {
+	const result = {
+		is_diagnostic: true,
+		file: "",
+		line: 0,
+		column: 0,
+		code: diagnostic.code,
+		severity: SEVERITIES[diagnostic.category] || "info",
+		message: ts.flattenDiagnosticMessageText(diagnostic.messageText, "\n"),
+	};
+	if (diagnostic.file && diagnostic.start !== undefined) {
+		const { line, character } = diagnostic.file.getLineAndCharacterOfPosition(diagnostic.start);
+		result.file = path.relative(WORK_DIR, diagnostic.file.fileName);
+		result.line = line + 1;
+		result.column = character + 1;
+	}
+	console.log(JSON.stringify(result));
+}
+
+function main() {
+	const entry = path.resolve(process.argv[2]);
+	const program = ts.createProgram(sourceFiles(WORK_DIR), {
+		target: ts.ScriptTarget.ES2022,
+		module: ts.ModuleKind.CommonJS,
+		strict: true,
+		esModuleInterop: true,
+		skipLibCheck: true,
+		sourceMap: true,
+		rootDir: WORK_DIR,
+		outDir: OUT_DIR,
+		types: ["node"],
+		typeRoots: ["/usr/lib/node_modules/@types"],
+	});
+
+	// Type errors don't stop the emit, the exercise decides whether they fail the submission.
+	const emitted = program.emit();
+	ts.getPreEmitDiagnostics(program).concat(emitted.diagnostics).forEach(report);
+
+	process.argv = [process.argv[0], entry, ...process.argv.slice(3)];
+	require(path.join(OUT_DIR, path.relative(WORK_DIR, entry)).replace(/\.tsx?$/, ".js"));
+}
+
+main();
+`
+)
+
+var spec = cmd.Spec{
+	NsjailConfigTemplate: nsjailConfigTemplate,
+	Traceback:            traceback.Node,
+	Explanations:         explain.Node,
+	SourceExtension:      "ts",
+	Runner: cmd.Script{
+		FileName: ".ts_runner.js",
+		Content:  runnerFile,
+		// Stack traces point at the TypeScript source instead of the emitted JavaScript.
+		Flags: []string{"--enable-source-maps"},
+	},
+	Output: parseDiagnostics,
+	// Loading the compiler and the type definitions takes most of a second on its own.
+	TimeLimit: 5,
+}
+
+type Driver struct {
+	logger    *logger.Logger
+	cmdPrefix string
+}
+
+func New(cmdPrefix string, logger *logger.Logger) *Driver {
+	return &Driver{
+		logger:    logger,
+		cmdPrefix: cmdPrefix,
+	}
+}
+
+func (d *Driver) Execute(ctx context.Context, executionRequest models.ExecutionRequest) (models.ExecuteResponse, error) {
+	return cmd.Execute(
+		ctx,
+		d.cmdPrefix,
+		spec,
+		executionRequest,
+	)
+}
+
+func (d *Driver) SetCmdPrefix(prefix string) error {
+	d.cmdPrefix = prefix
+	return nil
+}
+
+func (d *Driver) CmdPrefix() string {
+	return d.cmdPrefix
+}
+
+type diagnosticLine struct {
+	IsDiagnostic bool `json:"is_diagnostic"`
+	checkers.Diagnostic
+}
+
+// parseDiagnostics moves the diagnostics reported by the runner out of stdout.
+func parseDiagnostics(response *models.ExecuteResponse) {
+	lines := strings.SplitAfter(response.Stdout, "\n")
+	stdout := make([]string, 0, len(lines))
+	for _, line := range lines {
+		var d diagnosticLine
+		if err := json.Unmarshal([]byte(line), &d); err != nil || !d.IsDiagnostic {
+			stdout = append(stdout, line)
+			continue
+		}
+
+		response.Diagnostics = append(response.Diagnostics, d.Diagnostic)
+	}
+
+	response.Stdout = strings.Join(stdout, "")
+}
@@ -0,0 +1,54 @@
+// Package explain matches runtime errors against rules for common novice mistakes. A match is an
+// explanation key plus parameters, which the classroom renders in the learner's language.
+package explain
+
+import (
+	"codim/pkg/executors/drivers/models"
+	"regexp"
+	"strconv"
+)
+
+type Rule struct {
+	// Type is the exception type the rule applies to, any type when empty.
+	Type string
+	// Message is matched against the exception message, any message when nil.
+	// Its named groups become parameters of the explanation.
+	Message *regexp.Regexp
+	// Key is the translation key of the explanation.
+	Key string
+}
+
+// Rules are tried in order, so specific rules go before the general ones of the same type.
+type Rules []Rule
+
+// Explain returns the explanation of the first rule matching the error, if any.
+// The line of the error in the learner's code is passed along as the line parameter.
+func (rules Rules) Explain(err *models.RuntimeError) *models.Explanation {
+	for _, rule := range rules {
+		if rule.Type != "" && rule.Type != err.Type {
+			continue
+		}
+
+		params := make(map[string]string)
+		if rule.Message != nil {
+			m := rule.Message.FindStringSubmatch(err.Message)
+			if m == nil {
+				continue
+			}
+
+			for i, name := range rule.Message.SubexpNames() {
+				if name != "" && m[i] != "" {
+					params[name] = m[i]
+				}
+			}
+		}
+
+		if location := err.Location(); location != nil {
+			params["line"] = strconv.Itoa(location.Line)
+		}
+
+		return &models.Explanation{Key: rule.Key, Params: params}
+	}
+
+	return nil
+}
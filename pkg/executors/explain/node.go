@@ -0,0 +1,21 @@
+package explain
+
+import "regexp"
+
+var Node = Rules{
+	{Type: "TypeError", Message: regexp.MustCompile(`^Cannot read properties of (?P<value>undefined|null) \(reading '(?P<property>[^']+)'\)`), Key: "explanations.node.readPropertyOfNothing"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^Cannot set properties of (?P<value>undefined|null) \(setting '(?P<property>[^']+)'\)`), Key: "explanations.node.setPropertyOfNothing"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^(?P<name>.+) is not a function`), Key: "explanations.node.notAFunction"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^(?P<name>.+) is not iterable`), Key: "explanations.node.notIterable"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^Assignment to constant variable`), Key: "explanations.node.constAssignment"},
+	{Type: "ReferenceError", Message: regexp.MustCompile(`^Cannot access '(?P<name>[^']+)' before initialization`), Key: "explanations.node.beforeInitialization"},
+	{Type: "ReferenceError", Message: regexp.MustCompile(`^(?P<name>\S+) is not defined`), Key: "explanations.node.notDefined"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^Identifier '(?P<name>[^']+)' has already been declared`), Key: "explanations.node.alreadyDeclared"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^missing \) after argument list`), Key: "explanations.node.missingParenthesis"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^Unexpected end of input`), Key: "explanations.node.unexpectedEnd"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^Unexpected token '?(?P<token>[^']+)'?`), Key: "explanations.node.unexpectedToken"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^Invalid or unexpected token`), Key: "explanations.node.invalidToken"},
+	{Type: "RangeError", Message: regexp.MustCompile(`^Maximum call stack size exceeded`), Key: "explanations.node.recursion"},
+	{Type: "RangeError", Message: regexp.MustCompile(`^Invalid array length`), Key: "explanations.node.invalidArrayLength"},
+	{Type: "Error", Message: regexp.MustCompile(`^Cannot find module '(?P<module>[^']+)'`), Key: "explanations.node.moduleNotFound"},
+}
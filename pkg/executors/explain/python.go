@@ -0,0 +1,29 @@
+package explain
+
+import "regexp"
+
+var Python = Rules{
+	{Type: "IndentationError", Message: regexp.MustCompile(`^expected an indented block`), Key: "explanations.python.indentationExpected"},
+	{Type: "IndentationError", Message: regexp.MustCompile(`^unexpected indent`), Key: "explanations.python.unexpectedIndent"},
+	{Type: "IndentationError", Message: regexp.MustCompile(`^unindent does not match`), Key: "explanations.python.unindentMismatch"},
+	{Type: "TabError", Key: "explanations.python.tabError"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^'(?P<bracket>[(\[{])' was never closed`), Key: "explanations.python.bracketNeverClosed"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^expected ':'`), Key: "explanations.python.missingColon"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^unterminated string literal`), Key: "explanations.python.unterminatedString"},
+	{Type: "SyntaxError", Message: regexp.MustCompile(`^invalid syntax\. Maybe you meant '==' or ':=' instead of '='`), Key: "explanations.python.assignmentInCondition"},
+	{Type: "SyntaxError", Key: "explanations.python.invalidSyntax"},
+	{Type: "NameError", Message: regexp.MustCompile(`^name '(?P<name>\w+)' is not defined(?:\. Did you mean: '(?P<suggestion>\w+)'\?)?`), Key: "explanations.python.nameNotDefined"},
+	{Type: "UnboundLocalError", Message: regexp.MustCompile(`local variable '(?P<name>\w+)'`), Key: "explanations.python.unboundLocal"},
+	{Type: "IndexError", Message: regexp.MustCompile(`^(?P<kind>list|tuple|string) index out of range`), Key: "explanations.python.indexOutOfRange"},
+	{Type: "KeyError", Message: regexp.MustCompile(`^(?P<key>.+)$`), Key: "explanations.python.keyError"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^can only concatenate str \(not "(?P<type>\w+)"\) to str`), Key: "explanations.python.concatenateStr"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^unsupported operand type\(s\) for (?P<operator>\S+): '(?P<left>\w+)' and '(?P<right>\w+)'`), Key: "explanations.python.unsupportedOperand"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^'(?P<type>\w+)' object is not callable`), Key: "explanations.python.notCallable"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^'(?P<type>\w+)' object is not subscriptable`), Key: "explanations.python.notSubscriptable"},
+	{Type: "TypeError", Message: regexp.MustCompile(`^(?P<function>[\w.]+)\(\) missing \d+ required positional arguments?: (?P<arguments>.+)$`), Key: "explanations.python.missingArguments"},
+	{Type: "AttributeError", Message: regexp.MustCompile(`^'(?P<type>\w+)' object has no attribute '(?P<attribute>\w+)'`), Key: "explanations.python.noAttribute"},
+	{Type: "ValueError", Message: regexp.MustCompile(`^invalid literal for int\(\) with base 10: (?P<value>.+)$`), Key: "explanations.python.invalidIntLiteral"},
+	{Type: "ZeroDivisionError", Key: "explanations.python.zeroDivision"},
+	{Type: "RecursionError", Key: "explanations.python.recursion"},
+	{Type: "ModuleNotFoundError", Message: regexp.MustCompile(`^No module named '(?P<module>[\w.]+)'`), Key: "explanations.python.moduleNotFound"},
+}
@@ -27,7 +27,8 @@ func New(driver drivers.Driver, logger *logger.Logger, timeout time.Duration) *S
 func (s *Service) Execute(ctx context.Context, executionRequest models.ExecutionRequest) (models.ExecuteResponse, error) {
 	s.logger.Infof("Executing job %s", executionRequest.JobID)
 
-	execCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	// The harness runs of the checkers get their own time on top of the submission's.
+	execCtx, cancel := context.WithTimeout(ctx, s.timeout+executionRequest.CheckerTimeLimit())
 	defer cancel()
 
 	res, err := s.driver.Execute(execCtx, executionRequest)
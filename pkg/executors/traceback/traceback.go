@@ -0,0 +1,120 @@
+// Package traceback parses the uncaught exceptions interpreters print to stderr.
+package traceback
+
+import (
+	"codim/pkg/executors/drivers/models"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	pythonFramePattern     = regexp.MustCompile(`^\s*File "(.+)", line (\d+)(?:, in (.+))?$`)
+	pythonExceptionPattern = regexp.MustCompile(`^([A-Za-z_][\w.]*)(?::\s?(.*))?$`)
+	nodeFramePattern       = regexp.MustCompile(`^\s+at (?:(.+?) \()?(.+?):(\d+):(\d+)\)?$`)
+	nodeLocationPattern    = regexp.MustCompile(`^(/.+):(\d+)$`)
+	nodeExceptionPattern   = regexp.MustCompile(`^([A-Za-z_$][\w$.]*)(?::\s(.*))?$`)
+)
+
+const pythonTracebackHeader = "Traceback (most recent call last):"
+
+// Python parses the last traceback in stderr. Chained exceptions print one traceback each,
+// the last one being the exception that ended the program.
+func Python(stderr string) *models.RuntimeError {
+	lines := strings.Split(stderr, "\n")
+	start := 0
+	for i, line := range lines {
+		if line == pythonTracebackHeader {
+			start = i + 1
+		}
+	}
+
+	frames := make([]models.StackFrame, 0)
+	last := -1
+	for i := start; i < len(lines); i++ {
+		m := pythonFramePattern.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+
+		line, _ := strconv.Atoi(m[2])
+		frames = append(frames, models.StackFrame{File: m[1], Line: line, Function: m[3]})
+		last = i
+	}
+
+	if last == -1 {
+		return nil
+	}
+
+	// The frame lines are followed by indented source lines and then by the exception,
+	// whose message may span the remaining lines.
+	for i := last + 1; i < len(lines); i++ {
+		if lines[i] == "" || strings.HasPrefix(lines[i], " ") {
+			continue
+		}
+
+		m := pythonExceptionPattern.FindStringSubmatch(lines[i])
+		if m == nil {
+			break
+		}
+
+		message := strings.Join(append([]string{m[2]}, lines[i+1:]...), "\n")
+		return &models.RuntimeError{
+			Type:    m[1],
+			Message: strings.TrimSpace(message),
+			Frames:  frames,
+		}
+	}
+
+	return &models.RuntimeError{Frames: frames}
+}
+
+// Node parses an uncaught exception. Node prints the throwing line first, then the exception
+// and its stack, innermost frame first.
+func Node(stderr string) *models.RuntimeError {
+	lines := strings.Split(stderr, "\n")
+	first := slices.IndexFunc(lines, nodeFramePattern.MatchString)
+	if first == -1 {
+		return nil
+	}
+
+	frames := make([]models.StackFrame, 0)
+	for _, line := range lines[first:] {
+		m := nodeFramePattern.FindStringSubmatch(line)
+		if m == nil {
+			break
+		}
+
+		l, _ := strconv.Atoi(m[3])
+		column, _ := strconv.Atoi(m[4])
+		frames = append(frames, models.StackFrame{File: m[2], Line: l, Column: column, Function: m[1]})
+	}
+	slices.Reverse(frames)
+
+	// A syntax error only has frames of node's module loader, the location line names the failing file.
+	if m := nodeLocationPattern.FindStringSubmatch(lines[0]); m != nil {
+		l, _ := strconv.Atoi(m[2])
+		if !slices.ContainsFunc(frames, func(f models.StackFrame) bool { return f.File == m[1] && f.Line == l }) {
+			frames = append(frames, models.StackFrame{File: m[1], Line: l})
+		}
+	}
+
+	// The exception is the block between the blank line after the throwing line and the stack.
+	exception := first
+	for exception > 0 && lines[exception-1] != "" {
+		exception--
+	}
+
+	m := nodeExceptionPattern.FindStringSubmatch(lines[exception])
+	if m == nil {
+		return &models.RuntimeError{Frames: frames}
+	}
+
+	message := strings.Join(append([]string{m[2]}, lines[exception+1:first]...), "\n")
+	return &models.RuntimeError{
+		Type:    m[1],
+		Message: strings.TrimSpace(message),
+		Frames:  frames,
+	}
+}
@@ -0,0 +1,102 @@
+package traceback
+
+import (
+	"codim/pkg/executors/drivers/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPython(t *testing.T) {
+	stderr := `Traceback (most recent call last):
+  File "/work/main.py", line 2, in <module>
+    int("x")
+ValueError: invalid literal for int() with base 10: 'x'
+
+During handling of the above exception, another exception occurred:
+
+Traceback (most recent call last):
+  File "/work/main.py", line 7, in <module>
+    main()
+  File "/work/main.py", line 5, in main
+    raise RuntimeError("failed to parse\nsecond line")
+RuntimeError: failed to parse
+second line
+`
+
+	require.Equal(t, &models.RuntimeError{
+		Type:    "RuntimeError",
+		Message: "failed to parse\nsecond line",
+		Frames: []models.StackFrame{
+			{File: "/work/main.py", Line: 7, Function: "<module>"},
+			{File: "/work/main.py", Line: 5, Function: "main"},
+		},
+	}, Python(stderr))
+}
+
+func TestPythonSyntaxError(t *testing.T) {
+	stderr := `  File "/work/main.py", line 1
+    print("a"
+         ^
+SyntaxError: '(' was never closed
+`
+
+	require.Equal(t, &models.RuntimeError{
+		Type:    "SyntaxError",
+		Message: "'(' was never closed",
+		Frames:  []models.StackFrame{{File: "/work/main.py", Line: 1}},
+	}, Python(stderr))
+}
+
+func TestPythonWithoutTraceback(t *testing.T) {
+	require.Nil(t, Python("Killed\n"))
+	require.Nil(t, Python(""))
+}
+
+func TestNode(t *testing.T) {
+	stderr := `/work/main.js:3
+  throw new TypeError("bad input");
+  ^
+
+TypeError: bad input
+    at parse (/work/main.js:3:9)
+    at Object.<anonymous> (/work/main.js:6:1)
+    at node:internal/main/run_main_module:28:49
+
+Node.js v20.11.0
+`
+
+	require.Equal(t, &models.RuntimeError{
+		Type:    "TypeError",
+		Message: "bad input",
+		Frames: []models.StackFrame{
+			{File: "node:internal/main/run_main_module", Line: 28, Column: 49},
+			{File: "/work/main.js", Line: 6, Column: 1, Function: "Object.<anonymous>"},
+			{File: "/work/main.js", Line: 3, Column: 9, Function: "parse"},
+		},
+	}, Node(stderr))
+}
+
+func TestNodeSyntaxError(t *testing.T) {
+	stderr := `/work/main.js:2
+console.log("a"
+            ^^^
+
+SyntaxError: missing ) after argument list
+    at internalCompileFunction (node:internal/vm:76:18)
+    at Module._compile (node:internal/modules/cjs/loader:1270:20)
+
+Node.js v20.11.0
+`
+
+	r := Node(stderr)
+	require.NotNil(t, r)
+	require.Equal(t, "SyntaxError", r.Type)
+	require.Equal(t, "missing ) after argument list", r.Message)
+	// The failing file is added from the location line, after the loader frames.
+	require.Equal(t, models.StackFrame{File: "/work/main.js", Line: 2}, r.Frames[len(r.Frames)-1])
+}
+
+func TestNodeWithoutStack(t *testing.T) {
+	require.Nil(t, Node("Killed\n"))
+}
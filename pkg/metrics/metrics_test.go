@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scrape returns what the registry's handler serves.
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	return rec.Body.String()
+}
+
+func TestCounterAndGauge(t *testing.T) {
+	r := NewRegistry()
+	jobs := r.Counter("jobs_total", "Jobs processed.", "driver", "verdict")
+	inFlight := r.Gauge("jobs_in_flight", "Jobs running.")
+
+	jobs.Inc("python", "passed")
+	jobs.Add(2, "python", "passed")
+	jobs.Inc("node", "failed")
+	inFlight.Add(3)
+	inFlight.Add(-1)
+
+	require.Equal(t, `# HELP jobs_total Jobs processed.
+# TYPE jobs_total counter
+jobs_total{driver="node",verdict="failed"} 1
+jobs_total{driver="python",verdict="passed"} 3
+# HELP jobs_in_flight Jobs running.
+# TYPE jobs_in_flight gauge
+jobs_in_flight 2
+`, scrape(t, r))
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.Histogram("job_seconds", "Job time.", []float64{0.5, 1, 2.5}, "driver")
+
+	for _, v := range []float64{0.25, 0.5, 0.75, 2, 10} {
+		h.Observe(v, "python")
+	}
+
+	// Buckets are cumulative, a value on a bound falls into that bound's bucket,
+	// and the +Inf bucket counts every observation.
+	require.Equal(t, `# HELP job_seconds Job time.
+# TYPE job_seconds histogram
+job_seconds_bucket{driver="python",le="0.5"} 2
+job_seconds_bucket{driver="python",le="1"} 3
+job_seconds_bucket{driver="python",le="2.5"} 4
+job_seconds_bucket{driver="python",le="+Inf"} 5
+job_seconds_sum{driver="python"} 13.5
+job_seconds_count{driver="python"} 5
+`, scrape(t, r))
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	h := r.Histogram("setup_seconds", "Setup time.", []float64{1})
+	h.Observe(3)
+
+	require.Contains(t, scrape(t, r), `setup_seconds_bucket{le="1"} 0
+setup_seconds_bucket{le="+Inf"} 1
+setup_seconds_sum 3
+setup_seconds_count 1
+`)
+}
+
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("messages_total", "Messages by \"queue\",\nwith a \\ in the help.", "queue")
+	c.Inc("codexec.\"python\"\\\nnext")
+
+	output := scrape(t, r)
+	// Quotes are only escaped in label values.
+	require.Contains(t, output, `# HELP messages_total Messages by "queue",\nwith a \\ in the help.`+"\n")
+	require.Contains(t, output, `messages_total{queue="codexec.\"python\"\\\nnext"} 1`+"\n")
+	require.Len(t, strings.Split(strings.TrimSpace(output), "\n"), 3)
+}
+
+func TestFormatFloat(t *testing.T) {
+	require.Equal(t, "0.005", formatFloat(0.005))
+	require.Equal(t, "1.073741824e+09", formatFloat(1<<30))
+	require.Equal(t, "+Inf", formatFloat(math.Inf(1)))
+	require.Equal(t, "-Inf", formatFloat(math.Inf(-1)))
+}
+
+func TestLabelCountMismatch(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("jobs_total", "Jobs processed.", "driver")
+
+	require.Panics(t, func() { c.Inc() })
+}
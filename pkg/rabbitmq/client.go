@@ -2,22 +2,57 @@ package rabbitmq
 
 import (
 	"codim/pkg/utils/logger"
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// State is the state of the client's connection to RabbitMQ.
+type State string
+
+const (
+	StateConnected    State = "connected"
+	StateReconnecting State = "reconnecting"
+	StateClosed       State = "closed"
+)
+
+// ErrClosed is returned once the client has been closed.
+var ErrClosed = errors.New("rabbitmq client is closed")
+
+// Topology declares exchanges, queues and bindings on a channel.
+// It runs once when registered and again after every reconnection.
+type Topology func(ch *amqp.Channel) error
+
 type Client struct {
-	conn   *amqp.Connection
 	cfg    Config
 	logger *logger.Logger
+
+	mu    sync.RWMutex
+	conn  *amqp.Connection
+	state State
+	// ready is closed while the client is connected and replaced when the connection is lost.
+	ready      chan struct{}
+	done       chan struct{}
+	topologies []Topology
+	listeners  []func(State)
 }
 
 // NewClient creates a new RabbitMQ client.
+// The first connection has to succeed, later ones are retried with backoff until the client is closed.
 func NewClient(cfg Config, logger *logger.Logger) (*Client, error) {
 	if cfg.URL == "" {
 		return nil, fmt.Errorf("rabbitmq URL is required")
 	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = 500 * time.Millisecond
+	}
+	if cfg.MaxReconnectDelay < cfg.ReconnectDelay {
+		cfg.MaxReconnectDelay = cfg.ReconnectDelay
+	}
 
 	conn, err := amqp.Dial(cfg.URL)
 	if err != nil {
@@ -26,23 +61,241 @@ func NewClient(cfg Config, logger *logger.Logger) (*Client, error) {
 
 	logger.Info("Connected to RabbitMQ")
 
-	return &Client{
-		conn:   conn,
+	c := &Client{
 		cfg:    cfg,
 		logger: logger,
-	}, nil
+		conn:   conn,
+		state:  StateConnected,
+		ready:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	close(c.ready)
+
+	go c.watch(conn)
+
+	return c, nil
 }
 
-// Close closes the RabbitMQ connection.
+// Close closes the RabbitMQ connection and stops reconnecting.
 func (c *Client) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
+	c.mu.Lock()
+	if c.state == StateClosed {
+		c.mu.Unlock()
+		return nil
+	}
+	conn := c.conn
+	c.state = StateClosed
+	close(c.done)
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(StateClosed)
+	}
+
+	if conn != nil && !conn.IsClosed() {
+		return conn.Close()
+	}
+
+	return nil
+}
+
+// State returns the current state of the connection.
+func (c *Client) State() State {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+// OnStateChange registers a function called whenever the connection state changes.
+func (c *Client) OnStateChange(fn func(State)) {
+	c.mu.Lock()
+	c.listeners = append(c.listeners, fn)
+	c.mu.Unlock()
+}
+
+// Channel opens a channel on the current connection.
+// While reconnecting it waits for the connection to come back or for ctx to be done.
+func (c *Client) Channel(ctx context.Context) (*amqp.Channel, error) {
+	for {
+		c.mu.RLock()
+		conn, ready, state := c.conn, c.ready, c.state
+		c.mu.RUnlock()
+
+		if state == StateClosed {
+			return nil, ErrClosed
+		}
+
+		select {
+		case <-ready:
+		case <-c.done:
+			return nil, ErrClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		ch, err := conn.Channel()
+		if err == nil {
+			return ch, nil
+		}
+		if !errors.Is(err, amqp.ErrClosed) {
+			return nil, fmt.Errorf("failed to open channel: %w", err)
+		}
+
+		// The connection was lost in between, wait for the watcher to notice and reconnect.
+		if err := c.sleep(ctx, c.cfg.ReconnectDelay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Declare runs the topology on the current connection and registers it to run again after reconnecting.
+func (c *Client) Declare(ctx context.Context, topology Topology) error {
+	ch, err := c.Channel(ctx)
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := topology(ch); err != nil {
+		return err
 	}
 
+	c.mu.Lock()
+	c.topologies = append(c.topologies, topology)
+	c.mu.Unlock()
+
 	return nil
 }
 
-// Connection returns the underlying amqp connection.
-func (c *Client) Connection() *amqp.Connection {
-	return c.conn
+// DeclareQueue declares a durable queue and keeps it declared across reconnections.
+func (c *Client) DeclareQueue(ctx context.Context, queue string, args amqp.Table) error {
+	return c.Declare(ctx, func(ch *amqp.Channel) error {
+		if _, err := ch.QueueDeclare(queue, true, false, false, false, args); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", queue, err)
+		}
+		return nil
+	})
+}
+
+// watch waits for conn to be lost and reconnects.
+func (c *Client) watch(conn *amqp.Connection) {
+	for {
+		closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case err := <-closed:
+			if err != nil {
+				c.logger.Warnf("Lost connection to RabbitMQ: %v", err)
+			}
+		case <-c.done:
+			return
+		}
+
+		c.mu.Lock()
+		if c.state == StateClosed {
+			c.mu.Unlock()
+			return
+		}
+		c.ready = make(chan struct{})
+		c.mu.Unlock()
+		c.setState(StateReconnecting)
+
+		conn = c.reconnect()
+		if conn == nil {
+			return
+		}
+	}
+}
+
+// reconnect dials until a connection succeeds and its topology is redeclared.
+// It returns nil when the client is closed in the meantime.
+func (c *Client) reconnect() *amqp.Connection {
+	delay := c.cfg.ReconnectDelay
+	for attempt := 1; ; attempt++ {
+		if err := c.sleep(context.Background(), delay); err != nil {
+			return nil
+		}
+
+		conn, err := c.connect()
+		if err != nil {
+			c.logger.Warnf("Failed to reconnect to RabbitMQ (attempt %d): %v", attempt, err)
+			delay = min(delay*2, c.cfg.MaxReconnectDelay)
+			continue
+		}
+
+		c.mu.Lock()
+		if c.state == StateClosed {
+			c.mu.Unlock()
+			_ = conn.Close()
+			return nil
+		}
+		c.conn = conn
+		close(c.ready)
+		c.mu.Unlock()
+
+		c.logger.Infof("Reconnected to RabbitMQ after %d attempts", attempt)
+		c.setState(StateConnected)
+
+		return conn
+	}
+}
+
+// connect dials RabbitMQ and redeclares the registered topology.
+func (c *Client) connect() (*amqp.Connection, error) {
+	conn, err := amqp.Dial(c.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	c.mu.RLock()
+	topologies := c.topologies
+	c.mu.RUnlock()
+
+	for _, topology := range topologies {
+		if err := topology(ch); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to redeclare topology: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// setState records a state change. A closed client stays closed.
+func (c *Client) setState(state State) {
+	c.mu.Lock()
+	if c.state == StateClosed {
+		c.mu.Unlock()
+		return
+	}
+	c.state = state
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(state)
+	}
+}
+
+// sleep waits for d, returning early when ctx is done or the client is closed.
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -1,11 +1,17 @@
 package rabbitmq
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v11"
 )
 
 type Config struct {
 	URL string `env:"RABBITMQ_URL,required"`
+	// ReconnectDelay is the first wait after losing the connection, doubled on every failed attempt.
+	ReconnectDelay time.Duration `env:"RABBITMQ_RECONNECT_DELAY" envDefault:"500ms"`
+	// MaxReconnectDelay caps the wait between two reconnection attempts.
+	MaxReconnectDelay time.Duration `env:"RABBITMQ_MAX_RECONNECT_DELAY" envDefault:"30s"`
 }
 
 func LoadConfig() (Config, error) {
@@ -3,8 +3,10 @@ package rabbitmq
 import (
 	"codim/pkg/utils/logger"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -47,9 +49,88 @@ func WithArgs(args amqp.Table) ConsumerOption {
 	}
 }
 
+// WithPrefetchPerHandler overrides how many messages are prefetched per running handler, five by default.
+// Priority queues need a low one, the broker can only reorder the messages it hasn't delivered yet.
+func WithPrefetchPerHandler(prefetch int) ConsumerOption {
+	return func(c *Consumer) {
+		c.prefetchPerHandler = prefetch
+	}
+}
+
+// WithMaxConcurrency lets SetConcurrency raise the number of running handlers up to maxConcurrency.
+// Without it the concurrency given to Start is the maximum.
+func WithMaxConcurrency(maxConcurrency int) ConsumerOption {
+	return func(c *Consumer) {
+		c.maxConcurrency = maxConcurrency
+	}
+}
+
+// WithGate shares the limit on running handlers with the other consumers started with the same gate,
+// e.g. so a worker consuming several queues runs no more jobs than it would from one. They should all
+// be started, and have their concurrency set, with the same concurrency.
+func WithGate(g *Gate) ConsumerOption {
+	return func(c *Consumer) {
+		c.sharedGate = g
+	}
+}
+
+// WithDrainTimeout lets running handlers finish for up to timeout once the consumer is stopped.
+// Without it they are cancelled right away.
+func WithDrainTimeout(timeout time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		c.drainTimeout = timeout
+	}
+}
+
+// WithMaxAttempts bounds how many times a failing message is handled before it is abandoned.
+// Without it failing messages are requeued forever.
+func WithMaxAttempts(maxAttempts int) ConsumerOption {
+	return func(c *Consumer) {
+		c.maxAttempts = maxAttempts
+	}
+}
+
+// WithDeadLetter publishes abandoned messages to the exchange, routed by the queue they came from.
+func WithDeadLetter(exchange string) ConsumerOption {
+	return func(c *Consumer) {
+		c.deadLetterExchange = exchange
+	}
+}
+
+// WithAbandonHandler is called with every abandoned message, e.g. to tell its sender it failed.
+func WithAbandonHandler(handler AbandonHandler) ConsumerOption {
+	return func(c *Consumer) {
+		c.onAbandon = handler
+	}
+}
+
+// WithOutcomeHandler is called with the outcome of every message taken off the queue, e.g. to count retries.
+func WithOutcomeHandler(handler OutcomeHandler) ConsumerOption {
+	return func(c *Consumer) {
+		c.onOutcome = handler
+	}
+}
+
+// Outcome is what became of a consumed message.
+type Outcome string
+
+const (
+	// OutcomeAcked is a message handled successfully.
+	OutcomeAcked Outcome = "acked"
+	// OutcomeRequeued is a message nacked back to the queue as is.
+	OutcomeRequeued Outcome = "requeued"
+	// OutcomeRetried is a failed message republished with its attempts counted.
+	OutcomeRetried Outcome = "retried"
+	// OutcomeAbandoned is a message given up on and dead-lettered.
+	OutcomeAbandoned Outcome = "abandoned"
+)
+
+// OutcomeHandler receives the queue a message came from and what became of it.
+type OutcomeHandler func(queue string, outcome Outcome)
+
 // Consumer handles message consumption from RabbitMQ.
 type Consumer struct {
-	conn      *amqp.Connection
+	client    *Client
 	logger    *logger.Logger
 	tag       string
 	autoAck   bool
@@ -57,18 +138,40 @@ type Consumer struct {
 	noLocal   bool
 	noWait    bool
 	args      amqp.Table
+
+	maxAttempts        int
+	deadLetterExchange string
+	onAbandon          AbandonHandler
+	onOutcome          OutcomeHandler
+	drainTimeout       time.Duration
+	prefetchPerHandler int
+	maxConcurrency     int
+
+	// producer republishes retried and abandoned messages.
+	producer *Producer
+
+	sharedGate *Gate
+
+	// gate limits the running handlers, ch is the channel currently consumed from.
+	mu   sync.Mutex
+	gate *Gate
+	ch   *amqp.Channel
 }
 
 // Handler is the function signature for processing messages.
 // It receives the message body and returns an error.
 // If error is nil, the message is Acknowledged.
-// If error is not nil, the message is Negative Acknowledged and requeued.
+// If error is not nil, the message is Negative Acknowledged and requeued, or retried
+// and eventually abandoned when the consumer has a maximum number of attempts.
 type Handler func(ctx context.Context, body []byte) error
 
+// AbandonHandler receives a message that won't be retried anymore and the error of its last attempt.
+type AbandonHandler func(ctx context.Context, body []byte, err error)
+
 // NewConsumer creates a new Consumer instance.
 func (c *Client) NewConsumer() *Consumer {
 	return &Consumer{
-		conn:      c.conn,
+		client:    c,
 		logger:    c.logger,
 		tag:       "",
 		autoAck:   false,
@@ -76,28 +179,103 @@ func (c *Client) NewConsumer() *Consumer {
 		noLocal:   false,
 		noWait:    false,
 		args:      nil,
+
+		prefetchPerHandler: 5,
+		producer:           c.NewProducer(),
 	}
 }
 
 // Start begins consuming messages from the specified queue with the given concurrency.
-// It runs in the background until the context is cancelled or the connection is lost.
+// It runs until the context is cancelled or the client is closed, resuming the
+// subscription whenever the channel or the connection is lost. Cancelling the context
+// stops consuming, Start returns once the running handlers are drained.
 func (c *Consumer) Start(ctx context.Context, queue string, handler Handler, concurrency int, opts ...ConsumerOption) error {
 	for _, opt := range opts {
 		opt(c)
 	}
 
-	ch, err := c.conn.Channel()
+	c.logger.Infof("Starting consumer for queue %s with concurrency %d", queue, concurrency)
+	defer c.producer.Close()
+
+	c.mu.Lock()
+	if c.sharedGate != nil {
+		c.gate = c.sharedGate
+		c.gate.resize(concurrency)
+	} else {
+		c.gate = NewGate(concurrency)
+	}
+	c.mu.Unlock()
+
+	workers := max(concurrency, c.maxConcurrency)
+
+	for {
+		err := c.consume(ctx, queue, handler, workers)
+		if ctx.Err() != nil {
+			c.logger.Info("Consumer stopped")
+			return nil
+		}
+		if errors.Is(err, ErrClosed) {
+			return err
+		}
+
+		c.logger.Warnf("Consumer for queue %s interrupted, resuming: %v", queue, err)
+		if err := c.client.sleep(ctx, c.client.cfg.ReconnectDelay); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// SetConcurrency changes how many handlers run at once, up to the maximum concurrency, and scales
+// the prefetch count along. It may be called while consuming.
+func (c *Consumer) SetConcurrency(concurrency int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gate == nil {
+		return fmt.Errorf("consumer is not started")
+	}
+
+	c.gate.resize(concurrency)
+	if c.ch == nil {
+		return nil
+	}
+
+	if err := c.ch.Qos(concurrency*c.prefetchPerHandler, 0, false); err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	return nil
+}
+
+// consume subscribes to the queue once and processes messages with up to workers handlers until the channel closes.
+func (c *Consumer) consume(ctx context.Context, queue string, handler Handler, workers int) error {
+	ch, err := c.client.Channel(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to open channel: %w", err)
+		return err
 	}
+	defer ch.Close()
 
 	// Set QoS to ensure we don't overwhelm the consumers.
-	// Prefetch count is multiplied by 5 to ensure we don't overwhelm the consumers but still keep the workers busy.
-	if err := ch.Qos(concurrency*5, 0, false); err != nil {
-		_ = ch.Close()
+	// Prefetch count is multiplied by 5 by default to ensure we don't overwhelm the consumers but still keep the workers busy.
+	c.mu.Lock()
+	err = ch.Qos(c.gate.size()*c.prefetchPerHandler, 0, false)
+	if err == nil {
+		c.ch = ch
+	}
+	c.mu.Unlock()
+	if err != nil {
 		return fmt.Errorf("failed to set QoS: %w", err)
 	}
 
+	defer func() {
+		c.mu.Lock()
+		c.ch = nil
+		c.mu.Unlock()
+	}()
+
 	msgs, err := ch.ConsumeWithContext(
 		ctx,
 		queue,
@@ -109,64 +287,154 @@ func (c *Consumer) Start(ctx context.Context, queue string, handler Handler, con
 		c.args,
 	)
 	if err != nil {
-		_ = ch.Close()
 		return fmt.Errorf("failed to start consuming: %w", err)
 	}
 
-	c.logger.Infof("Starting consumer for queue %s with concurrency %d", queue, concurrency)
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	// Handlers run with their own context, so stopping the consumer doesn't interrupt them.
+	// They are only cancelled once the drain timeout has passed.
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	drained := make(chan struct{})
+	go c.drain(ctx, queue, drained, cancelJobs)
 
 	var wg sync.WaitGroup
-	wg.Add(concurrency)
+	wg.Add(workers)
 
-	for i := 0; i < concurrency; i++ {
+	for i := 0; i < workers; i++ {
 		go func() {
 			defer wg.Done()
 			for {
+				// Only as many handlers as the current concurrency take messages.
+				if !c.gate.acquire(ctx) {
+					return
+				}
+
 				select {
 				case <-ctx.Done():
+					c.gate.release()
 					return
 				case msg, ok := <-msgs:
 					if !ok {
-						c.logger.Warn("Channel closed, stopping worker")
+						c.gate.release()
+						return
+					}
+					// Prefetched messages go back to the queue once the consumer is stopping.
+					if ctx.Err() != nil {
+						c.requeue(queue, msg)
+						c.gate.release()
 						return
 					}
-					c.processMessage(ctx, msg, handler)
+					c.processMessage(jobCtx, queue, msg, handler)
+					c.gate.release()
 				}
 			}
 		}()
 	}
 
-	// Monitor context cancellation to cleanup
-	go func() {
-		<-ctx.Done()
-		c.logger.Info("Context cancelled, closing consumer channel")
-		_ = ch.Close()
-	}()
-
 	wg.Wait()
-	c.logger.Info("Consumer stopped")
+	close(drained)
 
-	return nil
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// Deliveries stop before the close notification is sent, so wait for the reason.
+	select {
+	case amqpErr, ok := <-closed:
+		if ok && amqpErr != nil {
+			return fmt.Errorf("channel closed: %w", amqpErr)
+		}
+	case <-time.After(time.Second):
+	}
+
+	return fmt.Errorf("channel closed")
 }
 
-func (c *Consumer) processMessage(ctx context.Context, msg amqp.Delivery, handler Handler) {
+// drain cancels the running handlers once ctx is done and the drain timeout has passed,
+// unless they all finished before.
+func (c *Consumer) drain(ctx context.Context, queue string, drained <-chan struct{}, cancelJobs context.CancelFunc) {
+	select {
+	case <-ctx.Done():
+	case <-drained:
+		return
+	}
+
+	if c.drainTimeout > 0 {
+		c.logger.Infof("Stopped consuming queue %s, waiting up to %s for running messages", queue, c.drainTimeout)
+
+		timer := time.NewTimer(c.drainTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			c.logger.Warnf("Drain timeout of queue %s reached, cancelling running messages", queue)
+		case <-drained:
+			return
+		}
+	}
+
+	cancelJobs()
+}
+
+func (c *Consumer) processMessage(ctx context.Context, queue string, msg amqp.Delivery, handler Handler) {
 	c.logger.Debugf("Received message: %s with message id %s from queue %s", string(msg.Body), msg.MessageId, msg.RoutingKey)
+	ctx = withDelivery(ctx, msg)
+
+	err := c.handle(ctx, msg, handler)
+	if err == nil {
+		if ackErr := msg.Ack(false); ackErr != nil {
+			c.logger.Errorf("Failed to ack message: %v", ackErr)
+		}
+		c.observe(queue, OutcomeAcked)
+		return
+	}
+
+	c.logger.Errorf("Failed to process message: %v", err)
+
+	// Interrupted by shutdown, the message gets a fresh attempt elsewhere.
+	if ctx.Err() != nil {
+		c.requeue(queue, msg)
+		return
+	}
+
+	if c.maxAttempts <= 0 {
+		c.requeue(queue, msg)
+		return
+	}
+
+	attempts := Attempts(msg) + 1
+	if attempts < c.maxAttempts && !IsPermanent(err) {
+		c.retry(ctx, queue, msg, attempts)
+		return
+	}
+
+	c.abandon(ctx, queue, msg, attempts, err)
+}
+
+// handle runs the handler, turning a panic into an error.
+func (c *Consumer) handle(ctx context.Context, msg amqp.Delivery, handler Handler) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			c.logger.Errorf("Panic in consumer handler: %v", r)
-			_ = msg.Nack(false, true)
+			err = Permanent(fmt.Errorf("panic in handler: %v", r))
 		}
 	}()
 
-	if err := handler(ctx, msg.Body); err != nil {
-		c.logger.Errorf("Failed to process message: %v", err)
-		if nackErr := msg.Nack(false, true); nackErr != nil {
-			c.logger.Errorf("Failed to nack message: %v", nackErr)
-		}
-		return
+	return handler(ctx, msg.Body)
+}
+
+func (c *Consumer) requeue(queue string, msg amqp.Delivery) {
+	if nackErr := msg.Nack(false, true); nackErr != nil {
+		c.logger.Errorf("Failed to nack message: %v", nackErr)
 	}
+	c.observe(queue, OutcomeRequeued)
+}
 
-	if ackErr := msg.Ack(false); ackErr != nil {
-		c.logger.Errorf("Failed to ack message: %v", ackErr)
+func (c *Consumer) observe(queue string, outcome Outcome) {
+	if c.onOutcome != nil {
+		c.onOutcome(queue, outcome)
 	}
 }
@@ -0,0 +1,34 @@
+package rabbitmq
+
+import (
+	"context"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type deliveryKey struct{}
+
+// withDelivery makes the delivery being handled available to the handler through its context.
+func withDelivery(ctx context.Context, msg amqp.Delivery) context.Context {
+	return context.WithValue(ctx, deliveryKey{}, msg)
+}
+
+// CorrelationID returns the correlation ID of the message being handled.
+func CorrelationID(ctx context.Context) string {
+	msg, _ := ctx.Value(deliveryKey{}).(amqp.Delivery)
+	return msg.CorrelationId
+}
+
+// MessageType returns the type of the message being handled, set by its sender WithType.
+func MessageType(ctx context.Context) string {
+	msg, _ := ctx.Value(deliveryKey{}).(amqp.Delivery)
+	return msg.Type
+}
+
+// PublishedAt returns when the message being handled was first published, or the zero time
+// when its sender didn't say.
+func PublishedAt(ctx context.Context) time.Time {
+	msg, _ := ctx.Value(deliveryKey{}).(amqp.Delivery)
+	return msg.Timestamp
+}
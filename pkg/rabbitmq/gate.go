@@ -0,0 +1,69 @@
+package rabbitmq
+
+import (
+	"context"
+	"sync"
+)
+
+// Gate limits how many handlers of a consumer run at once. The limit can change while consuming.
+// Consumers started WithGate share theirs, so the limit holds across them.
+type Gate struct {
+	mu     sync.Mutex
+	limit  int
+	active int
+	// changed is closed and replaced whenever a slot may have become available.
+	changed chan struct{}
+}
+
+func NewGate(limit int) *Gate {
+	return &Gate{
+		limit:   limit,
+		changed: make(chan struct{}),
+	}
+}
+
+// acquire waits for a free slot. It returns false when ctx is done first.
+func (g *Gate) acquire(ctx context.Context) bool {
+	for {
+		g.mu.Lock()
+		if g.active < g.limit {
+			g.active++
+			g.mu.Unlock()
+			return true
+		}
+		changed := g.changed
+		g.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (g *Gate) release() {
+	g.mu.Lock()
+	g.active--
+	g.broadcast()
+	g.mu.Unlock()
+}
+
+// resize changes the limit. Handlers running above a lowered limit finish their message first.
+func (g *Gate) resize(limit int) {
+	g.mu.Lock()
+	g.limit = limit
+	g.broadcast()
+	g.mu.Unlock()
+}
+
+func (g *Gate) size() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.limit
+}
+
+func (g *Gate) broadcast() {
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
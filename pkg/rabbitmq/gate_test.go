@@ -0,0 +1,48 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGate(t *testing.T) {
+	g := NewGate(1)
+	require.True(t, g.acquire(context.Background()))
+
+	// A full gate waits until ctx is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.False(t, g.acquire(ctx))
+
+	acquired := make(chan bool)
+	go func() { acquired <- g.acquire(context.Background()) }()
+
+	g.release()
+	require.True(t, <-acquired)
+}
+
+func TestGateResize(t *testing.T) {
+	g := NewGate(1)
+	require.True(t, g.acquire(context.Background()))
+
+	acquired := make(chan bool)
+	go func() { acquired <- g.acquire(context.Background()) }()
+
+	g.resize(2)
+	require.True(t, <-acquired)
+	require.Equal(t, 2, g.size())
+
+	// Lowering the limit lets running handlers finish, new ones wait until enough of them did.
+	g.resize(1)
+	g.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.False(t, g.acquire(ctx))
+
+	g.release()
+	require.True(t, g.acquire(context.Background()))
+}
@@ -4,24 +4,59 @@ import (
 	"codim/pkg/utils/logger"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// producerPoolSize is how many idle channels a producer keeps open.
+const producerPoolSize = 16
+
+// ErrUnroutable is returned when publishing a mandatory message no queue is bound to receive.
+var ErrUnroutable = errors.New("message is unroutable")
+
 // Producer handles message publishing to RabbitMQ.
+// Messages are published in confirm mode on pooled channels.
 type Producer struct {
-	conn   *amqp.Connection
-	logger *logger.Logger
+	client    *Client
+	logger    *logger.Logger
+	pool      chan *producerChannel
+	mandatory bool
+}
+
+// producerChannel is a pooled channel with the messages the broker returned on it.
+type producerChannel struct {
+	*amqp.Channel
+	// returns holds at most the return of the message being published, a channel publishes one message at a time.
+	returns chan amqp.Return
+}
+
+type ProducerOption func(*Producer)
+
+// WithMandatory publishes mandatory messages: the ones the broker can't route to a queue fail with ErrUnroutable
+// instead of being dropped.
+func WithMandatory() ProducerOption {
+	return func(p *Producer) {
+		p.mandatory = true
+	}
 }
 
 // NewProducer creates a new Producer instance.
-func (c *Client) NewProducer() *Producer {
-	return &Producer{
-		conn:   c.conn,
+func (c *Client) NewProducer(opts ...ProducerOption) *Producer {
+	p := &Producer{
+		client: c,
 		logger: c.logger,
+		pool:   make(chan *producerChannel, producerPoolSize),
+	}
+
+	for _, opt := range opts {
+		opt(p)
 	}
+
+	return p
 }
 
 // PublishOption allows customizing the publishing behavior.
@@ -34,6 +69,27 @@ func WithContentType(contentType string) PublishOption {
 	}
 }
 
+// WithTransient publishes the message without persisting it, for messages that are worthless after a broker restart.
+func WithTransient() PublishOption {
+	return func(p *amqp.Publishing) {
+		p.DeliveryMode = amqp.Transient
+	}
+}
+
+// WithType sets the type of the message, so consumers of mixed queues can tell messages apart.
+func WithType(messageType string) PublishOption {
+	return func(p *amqp.Publishing) {
+		p.Type = messageType
+	}
+}
+
+// WithPriority sets the priority of the message, only honoured by queues declared with x-max-priority.
+func WithPriority(priority uint8) PublishOption {
+	return func(p *amqp.Publishing) {
+		p.Priority = priority
+	}
+}
+
 // WithHeaders sets the headers of the message.
 func WithHeaders(headers amqp.Table) PublishOption {
 	return func(p *amqp.Publishing) {
@@ -41,31 +97,119 @@ func WithHeaders(headers amqp.Table) PublishOption {
 	}
 }
 
-// Publish sends a message to the specified exchange with the given routing key.
-// It creates a temporary channel for thread safety.
+// Publish sends a persistent message to the specified exchange with the given routing key and waits
+// for the broker to confirm it. While the client is reconnecting it waits for the connection to come
+// back, bounded by ctx. A nil error means the broker has taken responsibility for the message.
 func (p *Producer) Publish(ctx context.Context, exchange, routingKey string, body []byte, opts ...PublishOption) error {
-	ch, err := p.conn.Channel()
-	if err != nil {
-		return fmt.Errorf("failed to open channel: %w", err)
-	}
-	defer ch.Close()
-
 	msg := amqp.Publishing{
-		ContentType: "application/json",
-		Body:        body,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         body,
 	}
 
 	for _, opt := range opts {
 		opt(&msg)
 	}
 
-	if err := ch.PublishWithContext(ctx, exchange, routingKey, false, false, msg); err != nil {
+	return p.publish(ctx, exchange, routingKey, msg)
+}
+
+// publish sends msg on a pooled channel and waits for the broker to confirm it.
+func (p *Producer) publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	ch, err := p.channel(ctx)
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, p.mandatory, false, msg)
+	if err != nil {
+		_ = ch.Close()
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		// The confirmation may still arrive, the channel can't be reused for another message's wait.
+		_ = ch.Close()
+		return fmt.Errorf("failed to confirm message: %w", err)
+	}
+
+	// The broker returns an unroutable message before confirming it, so the return is already there.
+	var returned *amqp.Return
+	select {
+	case r := <-ch.returns:
+		returned = &r
+	default:
+	}
+	p.release(ch)
+
+	if !acked {
+		return fmt.Errorf("message was not confirmed by the broker")
+	}
+
+	if returned != nil {
+		return fmt.Errorf("%w: %s", ErrUnroutable, returned.ReplyText)
+	}
+
 	return nil
 }
 
+// Close closes the pooled channels.
+func (p *Producer) Close() {
+	for {
+		select {
+		case ch := <-p.pool:
+			_ = ch.Close()
+		default:
+			return
+		}
+	}
+}
+
+// channel takes an idle channel from the pool, or opens a new one in confirm mode.
+func (p *Producer) channel(ctx context.Context) (*producerChannel, error) {
+	for {
+		select {
+		case ch := <-p.pool:
+			// Channels of a lost connection are closed along with it.
+			if ch.IsClosed() {
+				continue
+			}
+			return ch, nil
+		default:
+		}
+
+		ch, err := p.client.Channel(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open channel: %w", err)
+		}
+
+		if err := ch.Confirm(false); err != nil {
+			_ = ch.Close()
+			return nil, fmt.Errorf("failed to put channel in confirm mode: %w", err)
+		}
+
+		return &producerChannel{
+			Channel: ch,
+			returns: ch.NotifyReturn(make(chan amqp.Return, 1)),
+		}, nil
+	}
+}
+
+// release puts the channel back into the pool, or closes it when the pool is full.
+func (p *Producer) release(ch *producerChannel) {
+	if ch.IsClosed() {
+		return
+	}
+
+	select {
+	case p.pool <- ch:
+	default:
+		_ = ch.Close()
+	}
+}
+
 // PublishObject publishes an object by marshaling it to JSON.
 // It only accepts struct types or pointers to structs, rejecting primitives.
 func (p *Producer) PublishObject(ctx context.Context, exchange, routingKey string, obj any, opts ...PublishOption) error {
@@ -0,0 +1,135 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ReplyTo returns where the sender of the message being handled expects its reply, if anywhere.
+func ReplyTo(ctx context.Context) (queue string, correlationID string, ok bool) {
+	msg, ok := ctx.Value(deliveryKey{}).(amqp.Delivery)
+	if !ok || msg.ReplyTo == "" {
+		return "", "", false
+	}
+	return msg.ReplyTo, msg.CorrelationId, true
+}
+
+// WithReplyTo asks the receiver to send its reply to the queue, tagged with the correlation ID.
+func WithReplyTo(queue string, correlationID string) PublishOption {
+	return func(p *amqp.Publishing) {
+		p.ReplyTo = queue
+		p.CorrelationId = correlationID
+	}
+}
+
+// WithCorrelationID tags the message with the correlation ID of the request it answers.
+func WithCorrelationID(correlationID string) PublishOption {
+	return func(p *amqp.Publishing) {
+		p.CorrelationId = correlationID
+	}
+}
+
+// Reply publishes the reply to the message being handled, straight to the queue its sender asked for.
+func (p *Producer) Reply(ctx context.Context, body []byte, opts ...PublishOption) error {
+	queue, correlationID, ok := ReplyTo(ctx)
+	if !ok {
+		return fmt.Errorf("message has no reply-to address")
+	}
+
+	return p.Publish(ctx, "", queue, body, append(opts, WithCorrelationID(correlationID))...)
+}
+
+// ReplyQueue is a queue of this process's own that receives the replies to its requests.
+// It only lives as long as the connection and is redeclared under the same name after reconnecting.
+type ReplyQueue struct {
+	client   *Client
+	producer *Producer
+	name     string
+
+	mu      sync.Mutex
+	pending map[string]chan []byte
+}
+
+// NewReplyQueue creates a reply queue named after prefix. It is declared by Declare.
+func (c *Client) NewReplyQueue(prefix string) *ReplyQueue {
+	return &ReplyQueue{
+		client:   c,
+		producer: c.NewProducer(),
+		name:     fmt.Sprintf("%s.%s", prefix, uuid.NewString()),
+		pending:  make(map[string]chan []byte),
+	}
+}
+
+// Name is the queue requests should name as their reply-to address.
+func (r *ReplyQueue) Name() string {
+	return r.name
+}
+
+// Declare declares the queue, exclusive to the connection and deleted along with it.
+func (r *ReplyQueue) Declare(ctx context.Context) error {
+	return r.client.Declare(ctx, func(ch *amqp.Channel) error {
+		if _, err := ch.QueueDeclare(r.name, false, true, true, false, nil); err != nil {
+			return fmt.Errorf("failed to declare reply queue %s: %w", r.name, err)
+		}
+		return nil
+	})
+}
+
+// Expect returns the publish option routing the reply to a request with the correlation ID to this queue.
+// The reply is handed to the handler given to Listen.
+func (r *ReplyQueue) Expect(correlationID string) PublishOption {
+	return WithReplyTo(r.name, correlationID)
+}
+
+// Listen consumes the replies until ctx is cancelled. Replies to a pending Call are returned by it,
+// every other reply is passed to the handler.
+func (r *ReplyQueue) Listen(ctx context.Context, handler Handler, concurrency int) error {
+	return r.client.NewConsumer().Start(ctx, r.name, func(ctx context.Context, body []byte) error {
+		correlationID := CorrelationID(ctx)
+
+		r.mu.Lock()
+		waiter, ok := r.pending[correlationID]
+		if ok {
+			delete(r.pending, correlationID)
+		}
+		r.mu.Unlock()
+
+		if ok {
+			waiter <- body
+			return nil
+		}
+
+		return handler(ctx, body)
+	}, concurrency)
+}
+
+// Call publishes a request and waits for its reply. The queue has to be listened to.
+func (r *ReplyQueue) Call(ctx context.Context, exchange, routingKey string, body []byte, opts ...PublishOption) ([]byte, error) {
+	correlationID := uuid.NewString()
+	waiter := make(chan []byte, 1)
+
+	r.mu.Lock()
+	r.pending[correlationID] = waiter
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, correlationID)
+		r.mu.Unlock()
+	}()
+
+	if err := r.producer.Publish(ctx, exchange, routingKey, body, append(opts, r.Expect(correlationID))...); err != nil {
+		return nil, err
+	}
+
+	select {
+	case reply := <-waiter:
+		return reply, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no reply to request %s: %w", correlationID, ctx.Err())
+	}
+}
@@ -0,0 +1,169 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// republishTimeout bounds waiting for the broker to confirm a retried or dead-lettered message,
+// e.g. while the connection is being restored.
+const republishTimeout = 30 * time.Second
+
+const (
+	// AttemptsHeader counts the failed attempts at handling a message.
+	AttemptsHeader = "x-attempts"
+	// LastErrorHeader holds the error of the last attempt of a dead-lettered message.
+	LastErrorHeader = "x-last-error"
+)
+
+// permanentError marks a failure that retrying won't fix, e.g. a malformed message.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so the message is abandoned right away instead of being retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped by Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Attempts returns how many times handling the message has failed before.
+func Attempts(msg amqp.Delivery) int {
+	switch v := msg.Headers[AttemptsHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// DeadLetterName is the name of the exchange and of the queue holding the abandoned messages of queue.
+func DeadLetterName(queue string) string {
+	return queue + ".dead"
+}
+
+// DeclareDeadLetter declares the dead-letter exchange and queue of queue and binds them,
+// so consumers started WithDeadLetter(DeadLetterName(queue)) keep their abandoned messages.
+func (c *Client) DeclareDeadLetter(ctx context.Context, queue string) error {
+	name := DeadLetterName(queue)
+	return c.Declare(ctx, func(ch *amqp.Channel) error {
+		if err := ch.ExchangeDeclare(name, "direct", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare dead-letter exchange %s: %w", name, err)
+		}
+		if _, err := ch.QueueDeclare(name, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare dead-letter queue %s: %w", name, err)
+		}
+		if err := ch.QueueBind(name, queue, name, false, nil); err != nil {
+			return fmt.Errorf("failed to bind dead-letter queue %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// retry publishes the message again at the back of the queue with its attempts counted,
+// then acks the delivery once the broker confirmed the copy. If publishing fails the delivery is requeued as is.
+func (c *Consumer) retry(ctx context.Context, queue string, msg amqp.Delivery, attempts int) {
+	headers := copyHeaders(msg.Headers)
+	headers[AttemptsHeader] = int32(attempts)
+
+	if err := c.republish(ctx, "", queue, republishing(msg, headers)); err != nil {
+		c.logger.Errorf("Failed to republish message for retry, requeueing: %v", err)
+		c.requeue(queue, msg)
+		return
+	}
+
+	c.logger.Warnf("Retrying message %s from queue %s (attempt %d of %d)", msg.MessageId, queue, attempts+1, c.maxAttempts)
+
+	if ackErr := msg.Ack(false); ackErr != nil {
+		c.logger.Errorf("Failed to ack message: %v", ackErr)
+	}
+	c.observe(queue, OutcomeRetried)
+}
+
+// abandon gives up on the message: it is moved to the dead-letter exchange, if any,
+// and handed to the abandon handler before being acked.
+func (c *Consumer) abandon(ctx context.Context, queue string, msg amqp.Delivery, attempts int, cause error) {
+	c.logger.Errorf("Abandoning message %s from queue %s after %d attempts: %v", msg.MessageId, queue, attempts, cause)
+
+	if c.deadLetterExchange != "" {
+		headers := copyHeaders(msg.Headers)
+		headers[AttemptsHeader] = int32(attempts)
+		headers[LastErrorHeader] = cause.Error()
+
+		if err := c.republish(ctx, c.deadLetterExchange, queue, republishing(msg, headers)); err != nil {
+			// Keep the message rather than losing it, it is retried once the dead-letter exchange is reachable.
+			c.logger.Errorf("Failed to dead-letter message, requeueing: %v", err)
+			c.requeue(queue, msg)
+			return
+		}
+	}
+
+	if c.onAbandon != nil {
+		c.onAbandon(ctx, msg.Body, cause)
+	}
+
+	if ackErr := msg.Ack(false); ackErr != nil {
+		c.logger.Errorf("Failed to ack message: %v", ackErr)
+	}
+	c.observe(queue, OutcomeAbandoned)
+}
+
+// republish publishes msg through the consumer's confirm-mode producer, not on the channel it consumes from,
+// so a nil error means the broker took the copy and the delivery can be acked.
+func (c *Consumer) republish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	ctx, cancel := context.WithTimeout(ctx, republishTimeout)
+	defer cancel()
+
+	return c.producer.publish(ctx, exchange, routingKey, msg)
+}
+
+// republishing copies the delivery's properties into a new message.
+func republishing(msg amqp.Delivery, headers amqp.Table) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    msg.DeliveryMode,
+		Priority:        msg.Priority,
+		CorrelationId:   msg.CorrelationId,
+		ReplyTo:         msg.ReplyTo,
+		Expiration:      msg.Expiration,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		AppId:           msg.AppId,
+		Body:            msg.Body,
+	}
+}
+
+func copyHeaders(headers amqp.Table) amqp.Table {
+	copied := make(amqp.Table, len(headers)+2)
+	for k, v := range headers {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,56 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttempts(t *testing.T) {
+	require.Equal(t, 0, Attempts(amqp.Delivery{}))
+	require.Equal(t, 2, Attempts(amqp.Delivery{Headers: amqp.Table{AttemptsHeader: int32(2)}}))
+	// Headers set by other clients may come back with another integer type.
+	require.Equal(t, 3, Attempts(amqp.Delivery{Headers: amqp.Table{AttemptsHeader: int64(3)}}))
+	require.Equal(t, 0, Attempts(amqp.Delivery{Headers: amqp.Table{AttemptsHeader: "3"}}))
+}
+
+func TestPermanent(t *testing.T) {
+	require.NoError(t, Permanent(nil))
+
+	cause := errors.New("malformed message")
+	err := fmt.Errorf("handling job: %w", Permanent(cause))
+	require.True(t, IsPermanent(err))
+	require.ErrorIs(t, err, cause)
+	require.Equal(t, "handling job: malformed message", err.Error())
+
+	require.False(t, IsPermanent(cause))
+}
+
+func TestRepublishing(t *testing.T) {
+	msg := amqp.Delivery{
+		Headers:       amqp.Table{"trace": "abc"},
+		ContentType:   "application/json",
+		DeliveryMode:  amqp.Persistent,
+		Priority:      3,
+		CorrelationId: "job",
+		ReplyTo:       "replies",
+		MessageId:     "id",
+		Type:          "job",
+		Body:          []byte(`{}`),
+	}
+
+	headers := copyHeaders(msg.Headers)
+	headers[AttemptsHeader] = int32(1)
+	republished := republishing(msg, headers)
+
+	require.Equal(t, amqp.Table{"trace": "abc", AttemptsHeader: int32(1)}, republished.Headers)
+	require.NotContains(t, msg.Headers, AttemptsHeader)
+	require.Equal(t, msg.Priority, republished.Priority)
+	require.Equal(t, msg.ReplyTo, republished.ReplyTo)
+	require.Equal(t, msg.CorrelationId, republished.CorrelationId)
+	require.Equal(t, msg.DeliveryMode, republished.DeliveryMode)
+	require.Equal(t, msg.Body, republished.Body)
+}
@@ -0,0 +1,243 @@
+package worker
+
+import (
+	"bufio"
+	"codim/pkg/rabbitmq"
+	"codim/pkg/utils/logger"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jobTimeSmoothing is the weight of the latest job in the average job time.
+	jobTimeSmoothing = 0.2
+	// slowdownFactor is how much slower than the fastest average jobs may get before the host
+	// counts as oversubscribed, e.g. because jobs wait for CPU or IO.
+	slowdownFactor = 1.5
+	// baselineDrift lets the fastest average creep up every interval, so a change in the mix of
+	// exercises doesn't keep the worker scaled down forever.
+	baselineDrift = 1.01
+)
+
+// AutoscaleConfig makes the worker adjust its concurrency to the load of the host it shares
+// with other workers, instead of running a fixed number of jobs.
+type AutoscaleConfig struct {
+	MinConcurrency int `json:"min_concurrency"`
+	MaxConcurrency int `json:"max_concurrency"`
+	// TargetLoad is the 1 minute load average per CPU to stay under.
+	TargetLoad float64 `json:"target_load"`
+	// MinAvailableMemory is the share of the host's memory to keep available.
+	MinAvailableMemory float64 `json:"min_available_memory"`
+	// Interval is the time between two adjustments, in seconds.
+	Interval int `json:"interval"`
+}
+
+// hostStats is the load of the host, as read from /proc.
+type hostStats struct {
+	// Load is the 1 minute load average per CPU.
+	Load float64
+	// AvailableMemory is the share of memory available to new processes.
+	AvailableMemory float64
+}
+
+// autoscaler sets the concurrency of a worker's consumers from the host load, the memory pressure
+// and the average job time.
+type autoscaler struct {
+	cfg    AutoscaleConfig
+	logger *logger.Logger
+
+	mu          sync.Mutex
+	consumers   []*rabbitmq.Consumer
+	concurrency int
+	running     int
+	// peak is the most jobs running at once since the last adjustment.
+	peak     int
+	jobTime  float64
+	baseline float64
+}
+
+func newAutoscaler(cfg AutoscaleConfig, logger *logger.Logger) *autoscaler {
+	return &autoscaler{
+		cfg:         cfg,
+		logger:      logger,
+		concurrency: cfg.MinConcurrency,
+	}
+}
+
+// add registers a consumer to scale, it has to be started with the current concurrency.
+func (a *autoscaler) add(consumer *rabbitmq.Consumer) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.consumers = append(a.consumers, consumer)
+	return a.concurrency
+}
+
+// track records a job from its start until the returned function is called.
+// It does nothing when autoscaling is disabled.
+func (a *autoscaler) track() func() {
+	if a == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+
+	a.mu.Lock()
+	a.running++
+	a.peak = max(a.peak, a.running)
+	a.mu.Unlock()
+
+	return func() {
+		seconds := time.Since(start).Seconds()
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		a.running--
+		if a.jobTime == 0 {
+			a.jobTime = seconds
+		} else {
+			a.jobTime = jobTimeSmoothing*seconds + (1-jobTimeSmoothing)*a.jobTime
+		}
+		if a.baseline == 0 || a.jobTime < a.baseline {
+			a.baseline = a.jobTime
+		}
+	}
+}
+
+// run adjusts the concurrency every interval until ctx is cancelled.
+func (a *autoscaler) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(a.cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats, err := readHostStats()
+		if err != nil {
+			a.logger.Warnf("Failed to read host load, keeping concurrency: %v", err)
+			continue
+		}
+
+		a.adjust(stats)
+	}
+}
+
+func (a *autoscaler) adjust(stats hostStats) {
+	a.mu.Lock()
+	previous := a.concurrency
+	a.concurrency = a.next(stats)
+	concurrency := a.concurrency
+	consumers := a.consumers
+	a.peak = a.running
+	a.baseline *= baselineDrift
+	a.mu.Unlock()
+
+	if concurrency == previous {
+		return
+	}
+
+	a.logger.Infof("Scaling worker concurrency from %d to %d (load %.2f per CPU, %.0f%% memory available)",
+		previous, concurrency, stats.Load, stats.AvailableMemory*100)
+
+	for _, consumer := range consumers {
+		if err := consumer.SetConcurrency(concurrency); err != nil {
+			a.logger.Warnf("Failed to scale consumer: %v", err)
+		}
+	}
+}
+
+// next decides the concurrency for the next interval. Memory pressure halves it, since running out
+// of memory fails every job at once. A busy CPU or slowing jobs take one job off, and a worker that
+// kept all its slots busy on a host with headroom gets one more.
+func (a *autoscaler) next(stats hostStats) int {
+	concurrency := a.concurrency
+	switch {
+	case stats.AvailableMemory < a.cfg.MinAvailableMemory:
+		concurrency /= 2
+	case stats.Load > a.cfg.TargetLoad:
+		concurrency--
+	case a.baseline > 0 && a.jobTime > a.baseline*slowdownFactor:
+		concurrency--
+	case a.peak >= a.concurrency && stats.Load < a.cfg.TargetLoad*0.8:
+		concurrency++
+	}
+
+	return min(max(concurrency, a.cfg.MinConcurrency), a.cfg.MaxConcurrency)
+}
+
+// readHostStats reads the load average and the available memory of the host.
+func readHostStats() (hostStats, error) {
+	loadavg, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return hostStats{}, err
+	}
+
+	fields := strings.Fields(string(loadavg))
+	if len(fields) == 0 {
+		return hostStats{}, fmt.Errorf("unexpected /proc/loadavg: %q", loadavg)
+	}
+
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return hostStats{}, fmt.Errorf("failed to parse load average: %w", err)
+	}
+
+	total, available, err := readMeminfo()
+	if err != nil {
+		return hostStats{}, err
+	}
+
+	return hostStats{
+		Load:            load / float64(runtime.NumCPU()),
+		AvailableMemory: float64(available) / float64(total),
+	}, nil
+}
+
+// readMeminfo returns the total and available memory in kB.
+func readMeminfo() (total int64, available int64, err error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "MemTotal:":
+			total = value
+		case "MemAvailable:":
+			available = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	if total == 0 {
+		return 0, 0, fmt.Errorf("MemTotal missing from /proc/meminfo")
+	}
+
+	return total, available, nil
+}
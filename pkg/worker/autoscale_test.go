@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testAutoscaler(concurrency int) *autoscaler {
+	a := newAutoscaler(AutoscaleConfig{
+		MinConcurrency:     2,
+		MaxConcurrency:     8,
+		TargetLoad:         1,
+		MinAvailableMemory: 0.1,
+	}, nil)
+	a.concurrency = concurrency
+	return a
+}
+
+func TestAutoscalerNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  int
+		peak     int
+		jobTime  float64
+		baseline float64
+		stats    hostStats
+		expected int
+	}{
+		{name: "memory pressure halves", current: 8, peak: 8, stats: hostStats{Load: 0.2, AvailableMemory: 0.05}, expected: 4},
+		{name: "memory pressure keeps the minimum", current: 3, stats: hostStats{Load: 0.2, AvailableMemory: 0.05}, expected: 2},
+		{name: "busy CPU takes one off", current: 5, peak: 5, stats: hostStats{Load: 1.5, AvailableMemory: 0.5}, expected: 4},
+		{name: "slowing jobs take one off", current: 5, peak: 5, jobTime: 3, baseline: 1, stats: hostStats{Load: 0.2, AvailableMemory: 0.5}, expected: 4},
+		{name: "busy slots with headroom add one", current: 5, peak: 5, jobTime: 1, baseline: 1, stats: hostStats{Load: 0.5, AvailableMemory: 0.5}, expected: 6},
+		{name: "no more than the maximum", current: 8, peak: 8, stats: hostStats{Load: 0.2, AvailableMemory: 0.5}, expected: 8},
+		{name: "idle slots keep it", current: 5, peak: 2, stats: hostStats{Load: 0.2, AvailableMemory: 0.5}, expected: 5},
+		{name: "load close to the target keeps it", current: 5, peak: 5, stats: hostStats{Load: 0.9, AvailableMemory: 0.5}, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := testAutoscaler(tt.current)
+			a.peak = tt.peak
+			a.jobTime = tt.jobTime
+			a.baseline = tt.baseline
+
+			require.Equal(t, tt.expected, a.next(tt.stats))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"codim/pkg/executors/drivers/models"
+	"codim/pkg/rabbitmq"
+	"codim/pkg/utils/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// cancelledRetention is how long a cancellation is remembered for jobs still waiting in a queue.
+const cancelledRetention = time.Hour
+
+// Canceller receives the job cancellations broadcast by the API and cancels the jobs running on
+// this host. It also remembers the cancelled jobs, so they are skipped once dequeued.
+type Canceller struct {
+	rmqClient *rabbitmq.Client
+	logger    *logger.Logger
+
+	mu        sync.Mutex
+	running   map[uuid.UUID]context.CancelFunc
+	cancelled map[uuid.UUID]time.Time
+}
+
+func NewCanceller(rmqClient *rabbitmq.Client, logger *logger.Logger) *Canceller {
+	return &Canceller{
+		rmqClient: rmqClient,
+		logger:    logger,
+		running:   make(map[uuid.UUID]context.CancelFunc),
+		cancelled: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Listen consumes the cancellations through a queue of this host's own until ctx is cancelled.
+func (c *Canceller) Listen(ctx context.Context) error {
+	queue := c.rmqClient.NewReplyQueue(models.CancelExchange)
+	if err := queue.Declare(ctx); err != nil {
+		return err
+	}
+
+	err := c.rmqClient.Declare(ctx, func(ch *amqp.Channel) error {
+		if err := ch.ExchangeDeclare(models.CancelExchange, "fanout", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange: %w", err)
+		}
+		if err := ch.QueueBind(queue.Name(), "", models.CancelExchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind queue: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return queue.Listen(ctx, c.messageHandler, 1)
+}
+
+func (c *Canceller) messageHandler(ctx context.Context, body []byte) error {
+	var cancellation models.JobCancellation
+	if err := json.Unmarshal(body, &cancellation); err != nil {
+		return rabbitmq.Permanent(fmt.Errorf("failed to unmarshal job cancellation: %w", err))
+	}
+
+	c.Cancel(cancellation.JobID)
+	return nil
+}
+
+// Cancel cancels the job if it runs here and remembers it in case it is still queued.
+func (c *Canceller) Cancel(jobID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for id, at := range c.cancelled {
+		if now.Sub(at) > cancelledRetention {
+			delete(c.cancelled, id)
+		}
+	}
+	c.cancelled[jobID] = now
+
+	if cancel, ok := c.running[jobID]; ok {
+		c.logger.Infof("Cancelling job %s", jobID)
+		cancel()
+	}
+}
+
+// Cancelled reports whether the job has been cancelled.
+func (c *Canceller) Cancelled(jobID uuid.UUID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.cancelled[jobID]
+	return ok
+}
+
+// Track registers a running job. The returned context is cancelled along with the job, done has
+// to be called once the job has finished.
+func (c *Canceller) Track(ctx context.Context, jobID uuid.UUID) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	c.mu.Lock()
+	c.running[jobID] = cancel
+	c.mu.Unlock()
+
+	return ctx, func() {
+		c.mu.Lock()
+		delete(c.running, jobID)
+		c.mu.Unlock()
+		cancel()
+	}
+}
@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"codim/pkg/executors/drivers/models"
 	"encoding/json"
 	"fmt"
 
@@ -13,6 +14,17 @@ type Config struct {
 	Queue        string `json:"queue" validate:"required"`
 	ResultsQueue string `json:"results_queue" validate:"required"`
 	Concurrency  int    `json:"concurrency"  envDefault:"10"`
+	// MaxAttempts is how many times a failing job is run before it is dead-lettered.
+	MaxAttempts int `json:"max_attempts" envDefault:"3"`
+	// Priorities declares the queues as priority queues, so graded submissions and staff jobs skip
+	// ahead of the rest. RabbitMQ can't change the arguments of an existing queue, so enabling it
+	// on a deployed queue requires deleting the queue first.
+	Priorities bool `json:"priorities"`
+	// Autoscale replaces the fixed concurrency with one adjusted to the host load.
+	Autoscale *AutoscaleConfig `json:"autoscale"`
+	// Environments are the package environments installed for the driver. The worker consumes
+	// one extra queue per environment, the concurrency bounds the jobs of all its queues together.
+	Environments []string `json:"environments"`
 }
 
 // workersConfig is used to load the JSON string from environment
@@ -22,7 +34,7 @@ type workersConfig struct {
 
 // LoadConfig loads worker configurations from a JSON environment variable
 // Workers are configured via the WORKERS environment variable as a JSON array.
-// Example: WORKERS='[{"driver":"node","queue":"codexec.node","concurrency":10},{"driver":"python","queue":"codexec.python","concurrency":10}]'
+// Example: WORKERS='[{"driver":"node","queue":"codexec.node","concurrency":10},{"driver":"python","queue":"codexec.python","concurrency":10,"environments":["data-science"]}]'
 func LoadConfig() ([]Config, error) {
 	validate := v.New()
 	var cfg workersConfig
@@ -39,17 +51,48 @@ func LoadConfig() ([]Config, error) {
 		if err := validate.Struct(worker); err != nil {
 			return nil, fmt.Errorf("invalid worker configuration: %w", err)
 		}
+
+		for _, environment := range worker.Environments {
+			if !models.ValidEnvironmentName(environment) {
+				return nil, fmt.Errorf("invalid environment name %q for queue %s", environment, worker.Queue)
+			}
+		}
 	}
 
 	if len(workers) == 0 {
 		return nil, fmt.Errorf("at least one worker configuration is required in WORKERS")
 	}
 
-	// Set default concurrency if not specified
+	// Set default concurrency and attempts if not specified
 	for i := range workers {
 		if workers[i].Concurrency == 0 {
 			workers[i].Concurrency = 10
 		}
+		if workers[i].MaxAttempts == 0 {
+			workers[i].MaxAttempts = 3
+		}
+
+		if autoscale := workers[i].Autoscale; autoscale != nil {
+			if autoscale.MinConcurrency == 0 {
+				autoscale.MinConcurrency = 1
+			}
+			if autoscale.MaxConcurrency == 0 {
+				autoscale.MaxConcurrency = workers[i].Concurrency
+			}
+			if autoscale.TargetLoad == 0 {
+				autoscale.TargetLoad = 0.9
+			}
+			if autoscale.MinAvailableMemory == 0 {
+				autoscale.MinAvailableMemory = 0.1
+			}
+			if autoscale.Interval == 0 {
+				autoscale.Interval = 10
+			}
+			if autoscale.MinConcurrency < 1 || autoscale.MaxConcurrency < autoscale.MinConcurrency {
+				return nil, fmt.Errorf("invalid autoscale concurrency range %d-%d for queue %s",
+					autoscale.MinConcurrency, autoscale.MaxConcurrency, workers[i].Queue)
+			}
+		}
 	}
 
 	return workers, nil
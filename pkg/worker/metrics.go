@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"codim/pkg/executors/drivers/models"
+	"codim/pkg/metrics"
+	"codim/pkg/rabbitmq"
+)
+
+// Job verdicts counted by the metrics.
+const (
+	verdictPassed    = "passed"
+	verdictFailed    = "failed"
+	verdictError     = "error"
+	verdictCancelled = "cancelled"
+)
+
+var (
+	// memoryBuckets range from a few megabytes to the memory limit of the largest sandboxes, in bytes.
+	memoryBuckets = []float64{8 << 20, 16 << 20, 32 << 20, 64 << 20, 128 << 20, 256 << 20, 512 << 20, 1 << 30}
+	// queueWaitBuckets go further than the execution ones, jobs can wait behind a long backlog.
+	queueWaitBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+)
+
+// Metrics are the job and connection metrics of the workers of a process.
+// A nil *Metrics records nothing.
+type Metrics struct {
+	jobs            *metrics.CounterVec
+	executionTime   *metrics.HistogramVec
+	memory          *metrics.HistogramVec
+	queueWait       *metrics.HistogramVec
+	setupTime       *metrics.HistogramVec
+	inFlight        *metrics.GaugeVec
+	messages        *metrics.CounterVec
+	connectionState *metrics.GaugeVec
+}
+
+// NewMetrics registers the worker metrics.
+func NewMetrics(registry *metrics.Registry) *Metrics {
+	return &Metrics{
+		jobs: registry.Counter("codexec_jobs_total",
+			"Jobs processed, by driver and verdict.", "driver", "verdict"),
+		executionTime: registry.Histogram("codexec_job_execution_seconds",
+			"Wall time of the sandboxed run of a job.", metrics.DefaultBuckets, "driver"),
+		memory: registry.Histogram("codexec_job_memory_bytes",
+			"Peak memory of the sandboxed run of a job.", memoryBuckets, "driver"),
+		queueWait: registry.Histogram("codexec_job_queue_wait_seconds",
+			"Time a job waited in the queue before a worker started it.", queueWaitBuckets, "driver"),
+		setupTime: registry.Histogram("codexec_sandbox_setup_seconds",
+			"Time spent preparing the sandbox of a job before running it.", metrics.DefaultBuckets, "driver"),
+		inFlight: registry.Gauge("codexec_jobs_in_flight",
+			"Jobs currently running.", "driver"),
+		messages: registry.Counter("codexec_messages_total",
+			"Messages taken off the job queues, by what became of them.", "queue", "outcome"),
+		connectionState: registry.Gauge("codexec_rabbitmq_connection_state",
+			"State of the RabbitMQ connection, 1 for the current state.", "state"),
+	}
+}
+
+// ObserveConnection keeps the connection state gauge in sync with the client.
+func (m *Metrics) ObserveConnection(client *rabbitmq.Client) {
+	if m == nil {
+		return
+	}
+
+	m.setConnectionState(client.State())
+	client.OnStateChange(m.setConnectionState)
+}
+
+func (m *Metrics) setConnectionState(current rabbitmq.State) {
+	for _, state := range []rabbitmq.State{rabbitmq.StateConnected, rabbitmq.StateReconnecting, rabbitmq.StateClosed} {
+		value := 0.0
+		if state == current {
+			value = 1
+		}
+		m.connectionState.Set(value, string(state))
+	}
+}
+
+// started records a job leaving the queue, it is in flight until the returned function is called.
+func (m *Metrics) started(driver string, queueWait float64) func() {
+	if m == nil {
+		return func() {}
+	}
+
+	if queueWait > 0 {
+		m.queueWait.Observe(queueWait, driver)
+	}
+
+	m.inFlight.Add(1, driver)
+	return func() { m.inFlight.Add(-1, driver) }
+}
+
+// executed records the verdict of a job and the resources its run took.
+func (m *Metrics) executed(driver string, r models.ExecuteResponse) {
+	if m == nil {
+		return
+	}
+
+	verdict := verdictFailed
+	if r.Passed() {
+		verdict = verdictPassed
+	}
+	m.jobs.Inc(driver, verdict)
+
+	m.executionTime.Observe(r.Time, driver)
+	m.memory.Observe(float64(r.Memory)*(1<<20), driver)
+	m.setupTime.Observe(r.SetupTime, driver)
+}
+
+// finished records a job without a regular result, e.g. cancelled or failing to execute.
+func (m *Metrics) finished(driver string, verdict string) {
+	if m == nil {
+		return
+	}
+	m.jobs.Inc(driver, verdict)
+}
+
+// observeMessage is the consumers' outcome handler.
+func (m *Metrics) observeMessage(queue string, outcome rabbitmq.Outcome) {
+	if m == nil {
+		return
+	}
+	m.messages.Inc(queue, string(outcome))
+}
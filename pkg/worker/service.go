@@ -2,20 +2,41 @@ package worker
 
 import (
 	"codim/pkg/executors"
+	"codim/pkg/executors/drivers/models"
 	"codim/pkg/rabbitmq"
 	"codim/pkg/utils/logger"
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// eventPublishTimeout bounds how long a job waits for its events to be published.
+const eventPublishTimeout = 2 * time.Second
+
 type Worker struct {
+	// name identifies the worker in job events, the host it runs on.
+	name            string
+	driver          string
 	concurrency     int
+	maxAttempts     int
+	priorities      bool
 	queue           string
+	environments    []string
 	resultsQueue    string
+	shutdownTimeout time.Duration
 	ctx             context.Context
 	cancel          context.CancelFunc
 	rmqClient       *rabbitmq.Client
 	resProducer     *rabbitmq.Producer
+	canceller       *Canceller
+	autoscaler      *autoscaler
+	metrics         *Metrics
 	executorService *executors.Service
 	logger          *logger.Logger
 }
@@ -25,72 +46,248 @@ func New(
 	executorService *executors.Service,
 	logger *logger.Logger,
 	cfg Config,
+	shutdownTimeout time.Duration,
+	canceller *Canceller,
+	metrics *Metrics,
 ) *Worker {
 	resProducer := rmqClient.NewProducer()
+	name, err := os.Hostname()
+	if err != nil {
+		name = cfg.Queue
+	}
+
+	var scaler *autoscaler
+	if cfg.Autoscale != nil {
+		scaler = newAutoscaler(*cfg.Autoscale, logger)
+	}
+
 	return &Worker{
+		name:            name,
+		driver:          cfg.Driver,
 		concurrency:     cfg.Concurrency,
+		maxAttempts:     cfg.MaxAttempts,
+		priorities:      cfg.Priorities,
 		queue:           cfg.Queue,
+		environments:    cfg.Environments,
 		resultsQueue:    cfg.ResultsQueue,
+		shutdownTimeout: shutdownTimeout,
 		rmqClient:       rmqClient,
 		resProducer:     resProducer,
+		canceller:       canceller,
+		autoscaler:      scaler,
+		metrics:         metrics,
 		executorService: executorService,
 		logger:          logger,
 	}
 }
 
+// Start consumes the worker's queues until ctx is cancelled or Stop is called. Jobs running by then
+// get the shutdown timeout to finish and publish their results, Start returns once they are done.
 func (w *Worker) Start(ctx context.Context) error {
 	w.ctx, w.cancel = context.WithCancel(ctx)
 
-	if err := w.ensureQueueExists(); err != nil {
-		return err
+	queues := w.queues()
+	for _, queue := range queues {
+		if err := w.rmqClient.DeclareQueue(w.ctx, queue, w.queueArgs()); err != nil {
+			return err
+		}
+		if err := w.rmqClient.DeclareDeadLetter(w.ctx, queue); err != nil {
+			return err
+		}
 	}
 
-	consumer := w.rmqClient.NewConsumer()
-	err := consumer.Start(w.ctx, w.queue, w.messageHandler, w.concurrency)
-	if err != nil {
-		return err
+	// The consumers share one gate, so the worker runs no more jobs at once than its concurrency
+	// however many queues it consumes.
+	gate := rabbitmq.NewGate(w.concurrency)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(queues))
+	for _, queue := range queues {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			consumer := w.rmqClient.NewConsumer()
+			opts := []rabbitmq.ConsumerOption{
+				rabbitmq.WithMaxAttempts(w.maxAttempts),
+				rabbitmq.WithDeadLetter(rabbitmq.DeadLetterName(queue)),
+				rabbitmq.WithAbandonHandler(w.abandonHandler),
+				rabbitmq.WithDrainTimeout(w.shutdownTimeout),
+				rabbitmq.WithOutcomeHandler(w.metrics.observeMessage),
+				rabbitmq.WithGate(gate),
+			}
+			if w.priorities {
+				// Jobs only get reordered while they wait in the queue, so take no more than can run.
+				opts = append(opts, rabbitmq.WithPrefetchPerHandler(1))
+			}
+
+			concurrency := w.concurrency
+			if w.autoscaler != nil {
+				concurrency = w.autoscaler.add(consumer)
+				opts = append(opts, rabbitmq.WithMaxConcurrency(w.autoscaler.cfg.MaxConcurrency))
+			}
+
+			err := consumer.Start(w.ctx, queue, w.messageHandler, concurrency, opts...)
+			if err != nil {
+				errs <- fmt.Errorf("failed to consume queue %s: %w", queue, err)
+				// One queue failing stops the others, so the worker is restarted as a whole.
+				w.cancel()
+			}
+		}()
 	}
 
-	return nil
+	if w.autoscaler != nil {
+		go w.autoscaler.run(w.ctx)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// queueArgs declares the job queues as priority queues when priorities are enabled.
+func (w *Worker) queueArgs() amqp.Table {
+	if !w.priorities {
+		return nil
+	}
+	return amqp.Table{"x-max-priority": models.MaxJobPriority}
+}
+
+// queues are the worker's own queue and one queue per package environment it has installed.
+func (w *Worker) queues() []string {
+	queues := []string{w.queue}
+	for _, environment := range w.environments {
+		queues = append(queues, models.EnvironmentQueue(w.queue, environment))
+	}
+	return queues
 }
 
 func (w *Worker) Stop() error {
 	w.cancel()
+	w.resProducer.Close()
 	return nil
 }
 
 func (w *Worker) messageHandler(ctx context.Context, body []byte) error {
 	executionRequest, err := w.executorService.ParseExecutionRequest(body)
 	if err != nil {
-		return err
+		// A malformed request fails the same way every time.
+		return rabbitmq.Permanent(err)
+	}
+
+	jobID := executionRequest.JobID
+	if w.canceller.Cancelled(jobID) {
+		w.logger.Infof("Skipping cancelled job %s", jobID)
+		w.metrics.finished(w.driver, verdictCancelled)
+		return w.publishResult(ctx, models.CancelledResponse(jobID))
 	}
 
-	r, err := w.executorService.Execute(ctx, executionRequest)
+	startedAt := time.Now()
+	w.publishEvent(ctx, jobID, models.JobStatusStarted, startedAt)
+
+	defer w.autoscaler.track()()
+	defer w.metrics.started(w.driver, queueWait(ctx, startedAt))()
+
+	jobCtx, done := w.canceller.Track(ctx, jobID)
+	r, err := w.executorService.Execute(jobCtx, executionRequest)
+	done()
+
+	// The drain timeout of a shutdown killed the sandbox, the consumer requeues the job for another worker.
+	if ctx.Err() != nil {
+		return fmt.Errorf("job %s interrupted by shutdown: %w", jobID, ctx.Err())
+	}
+
+	// The sandbox was killed halfway, whatever it returned is meaningless.
+	if w.canceller.Cancelled(jobID) {
+		w.metrics.finished(w.driver, verdictCancelled)
+		return w.publishResult(ctx, models.CancelledResponse(jobID))
+	}
 	if err != nil {
+		w.metrics.finished(w.driver, verdictError)
 		return err
 	}
 
-	r.JobID = executionRequest.JobID
+	w.metrics.executed(w.driver, r)
+
+	finishedAt := time.Now()
+	w.publishEvent(ctx, jobID, models.JobStatusFinished, finishedAt)
 
-	err = w.resProducer.PublishObject(ctx, w.resultsQueue, "", r)
+	r.JobID = jobID
+	r.Timing = models.NewJobTiming(rabbitmq.PublishedAt(ctx), startedAt, finishedAt)
+
+	// The job is only acked once the broker confirmed its result, otherwise it is retried.
+	err = w.publishResult(ctx, r)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to publish result of job %s: %w", jobID, err)
 	}
 
 	return nil
 }
 
-func (w *Worker) ensureQueueExists() error {
-	ch, err := w.rmqClient.Connection().Channel()
+// queueWait is how long the job waited in the queue, zero when its publish time is unknown.
+func queueWait(ctx context.Context, startedAt time.Time) float64 {
+	publishedAt := rabbitmq.PublishedAt(ctx)
+	if publishedAt.IsZero() {
+		return 0
+	}
+	return startedAt.Sub(publishedAt).Seconds()
+}
+
+// abandonHandler tells the API a job won't be run, so the learner gets a result instead of waiting forever.
+func (w *Worker) abandonHandler(ctx context.Context, body []byte, cause error) {
+	// The request may be malformed, only its job ID is needed.
+	var job struct {
+		JobID uuid.UUID `json:"job_id"`
+	}
+	if err := json.Unmarshal(body, &job); err != nil || job.JobID == uuid.Nil {
+		w.logger.Errorf("Abandoned a job without a job ID, no failure result sent: %v", cause)
+		return
+	}
+
+	r := models.FailedResponse(job.JobID, "The submission could not be executed")
+	if err := w.publishResult(ctx, r); err != nil {
+		w.logger.Errorf("Failed to publish failure result of job %s: %v", job.JobID, err)
+	}
+}
+
+// publishResult sends the result straight to the API instance that queued the job. Jobs without
+// a reply-to address, e.g. queued by an older API, have their result broadcast on the results exchange.
+func (w *Worker) publishResult(ctx context.Context, r models.ExecuteResponse) error {
+	if _, _, ok := rabbitmq.ReplyTo(ctx); !ok {
+		return w.resProducer.PublishObject(ctx, w.resultsQueue, "", r)
+	}
+
+	body, err := json.Marshal(r)
 	if err != nil {
-		return fmt.Errorf("failed to open channel: %w", err)
+		return fmt.Errorf("failed to marshal result: %w", err)
 	}
-	defer ch.Close()
 
-	_, err = ch.QueueDeclare(w.queue, true, false, false, false, nil)
+	return w.resProducer.Reply(ctx, body)
+}
+
+// publishEvent tells the API instance that queued the job about its progress. Events are best effort,
+// a lost one only delays the learner's feedback until the result arrives.
+func (w *Worker) publishEvent(ctx context.Context, jobID uuid.UUID, status models.JobStatus, timestamp time.Time) {
+	if _, _, ok := rabbitmq.ReplyTo(ctx); !ok {
+		return
+	}
+
+	body, err := json.Marshal(models.JobEvent{
+		JobID:     jobID,
+		Status:    status,
+		Worker:    w.name,
+		Timestamp: timestamp,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+		w.logger.Warnf("Failed to marshal %s event of job %s: %v", status, jobID, err)
+		return
 	}
 
-	return nil
+	ctx, cancel := context.WithTimeout(ctx, eventPublishTimeout)
+	defer cancel()
+
+	if err := w.resProducer.Reply(ctx, body, rabbitmq.WithType(models.JobEventMessageType), rabbitmq.WithTransient()); err != nil {
+		w.logger.Warnf("Failed to publish %s event of job %s: %v", status, jobID, err)
+	}
 }
@@ -209,9 +209,11 @@ export const getGetCoursesUuidResponseMock = (
             faker.string.alpha({ length: { min: 10, max: 20 } }),
             undefined,
           ]),
+          deterministic: faker.helpers.arrayElement([faker.datatype.boolean(), undefined]),
           lesson_uuid: faker.string.alpha({ length: { min: 10, max: 20 } }),
           modified_at: faker.string.alpha({ length: { min: 10, max: 20 } }),
           order_index: faker.number.int({ min: undefined, max: undefined }),
+          pass_threshold: faker.number.int({ min: undefined, max: undefined }),
           quiz_data: faker.helpers.arrayElement([{}, undefined]),
           reward: faker.number.int({ min: undefined, max: undefined }),
           translation: {
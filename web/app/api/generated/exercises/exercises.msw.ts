@@ -51,9 +51,11 @@ export const getGetExercisesResponseMock =
         faker.string.alpha({ length: { min: 10, max: 20 } }),
         undefined,
       ]),
+      deterministic: faker.helpers.arrayElement([faker.datatype.boolean(), undefined]),
       lesson_uuid: faker.string.alpha({ length: { min: 10, max: 20 } }),
       modified_at: faker.string.alpha({ length: { min: 10, max: 20 } }),
       order_index: faker.number.int({ min: undefined, max: undefined }),
+      pass_threshold: faker.number.int({ min: undefined, max: undefined }),
       quiz_data: faker.helpers.arrayElement([{}, undefined]),
       reward: faker.number.int({ min: undefined, max: undefined }),
       translation: {
@@ -146,9 +148,11 @@ export const getPostExercisesCreateResponseMock = (
     faker.string.alpha({ length: { min: 10, max: 20 } }),
     undefined,
   ]),
+  deterministic: faker.helpers.arrayElement([faker.datatype.boolean(), undefined]),
   lesson_uuid: faker.string.alpha({ length: { min: 10, max: 20 } }),
   modified_at: faker.string.alpha({ length: { min: 10, max: 20 } }),
   order_index: faker.number.int({ min: undefined, max: undefined }),
+  pass_threshold: faker.number.int({ min: undefined, max: undefined }),
   quiz_data: faker.helpers.arrayElement([{}, undefined]),
   reward: faker.number.int({ min: undefined, max: undefined }),
   translation: {
@@ -218,9 +222,11 @@ export const getPutExercisesUpdateResponseMock = (
     faker.string.alpha({ length: { min: 10, max: 20 } }),
     undefined,
   ]),
+  deterministic: faker.helpers.arrayElement([faker.datatype.boolean(), undefined]),
   lesson_uuid: faker.string.alpha({ length: { min: 10, max: 20 } }),
   modified_at: faker.string.alpha({ length: { min: 10, max: 20 } }),
   order_index: faker.number.int({ min: undefined, max: undefined }),
+  pass_threshold: faker.number.int({ min: undefined, max: undefined }),
   quiz_data: faker.helpers.arrayElement([{}, undefined]),
   reward: faker.number.int({ min: undefined, max: undefined }),
   translation: {
@@ -284,9 +290,11 @@ export const getGetExercisesUuidResponseMock = (
     faker.string.alpha({ length: { min: 10, max: 20 } }),
     undefined,
   ]),
+  deterministic: faker.helpers.arrayElement([faker.datatype.boolean(), undefined]),
   lesson_uuid: faker.string.alpha({ length: { min: 10, max: 20 } }),
   modified_at: faker.string.alpha({ length: { min: 10, max: 20 } }),
   order_index: faker.number.int({ min: undefined, max: undefined }),
+  pass_threshold: faker.number.int({ min: undefined, max: undefined }),
   quiz_data: faker.helpers.arrayElement([{}, undefined]),
   reward: faker.number.int({ min: undefined, max: undefined }),
   translation: {
@@ -192,6 +192,7 @@ export const getGetMeExercisesExerciseUuidResponseMock = (
   overrideResponse: Partial<ModelsUserExercise> = {},
 ): ModelsUserExercise => ({
   attempts: faker.number.int({ min: undefined, max: undefined }),
+  best_score: faker.number.int({ min: undefined, max: undefined }),
   completed_at: faker.helpers.arrayElement([
     faker.string.alpha({ length: { min: 10, max: 20 } }),
     undefined,
@@ -9,10 +9,12 @@ import type { DbExerciseType } from "./dbExerciseType";
 
 export interface ExercisesCreateExerciseRequest {
   description: string;
+  deterministic?: boolean;
   language: string;
   lesson_uuid: string;
   name: string;
   order_index: number;
+  pass_threshold?: number;
   reward: number;
   type: DbExerciseType;
 }
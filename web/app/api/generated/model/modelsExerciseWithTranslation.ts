@@ -14,9 +14,11 @@ export interface ModelsExerciseWithTranslation {
   code_data?: ModelsExerciseCodeData;
   created_at: string;
   deleted_at?: string;
+  deterministic?: boolean;
   lesson_uuid: string;
   modified_at: string;
   order_index: number;
+  pass_threshold: number;
   quiz_data?: ModelsExerciseQuizData;
   reward: number;
   translation: ModelsExerciseTranslation;
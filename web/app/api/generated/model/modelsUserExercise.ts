@@ -9,6 +9,7 @@ import type { ModelsUserExerciseSubmission } from "./modelsUserExerciseSubmissio
 
 export interface ModelsUserExercise {
   attempts: number;
+  best_score: number;
   completed_at?: string;
   exercise_uuid: string;
   last_accessed_at?: string;
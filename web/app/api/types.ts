@@ -9,10 +9,78 @@ export interface UserExerciseCodeData {
     children?: UserExerciseCodeData[];
 }
 
+export interface Measurement {
+    size: number;
+    time: number;
+    reference_time?: number;
+}
+
 export interface CheckerResult {
     type: string;
     success: boolean;
     message: string;
+    severity?: "error" | "warning" | "info";
+    optional?: boolean;
+    file?: string;
+    line?: number;
+    column?: number;
+    weight?: number;
+    measurements?: Measurement[];
+}
+
+export type SubmissionMode = "run" | "submit";
+
+export type JobStatus = "queued" | "started" | "finished";
+
+export interface JobEvent {
+    type: "job_event";
+    job_id: string;
+    status: JobStatus;
+    worker?: string;
+    timestamp: string;
+}
+
+export interface JobTiming {
+    queued_at?: string;
+    started_at: string;
+    finished_at: string;
+    queue_wait: number;
+    execution_time: number;
+}
+
+export interface StackFrame {
+    file: string;
+    line: number;
+    column?: number;
+    function?: string;
+    user: boolean;
+}
+
+export interface Explanation {
+    key: string;
+    params?: Record<string, string>;
+}
+
+export interface RuntimeError {
+    type: string;
+    message: string;
+    frames: StackFrame[];
+    explanation?: Explanation;
+}
+
+export interface Diagnostic {
+    file: string;
+    line: number;
+    column: number;
+    code?: number;
+    severity: "error" | "warning" | "info";
+    message: string;
+}
+
+export interface ResultSet {
+    columns: string[];
+    rows: unknown[][];
+    truncated?: boolean;
 }
 
 export interface ExecuteResponse {
@@ -24,7 +92,16 @@ export interface ExecuteResponse {
     memory: number;
     cpu: number;
     checker_results: CheckerResult[];
+    result_sets?: ResultSet[];
+    diagnostics?: Diagnostic[];
+    error?: RuntimeError;
+    failure?: string;
+    timing?: JobTiming;
+    cancelled?: boolean;
+    mode: SubmissionMode;
     passed: boolean;
+    score: number;
+    reward: number;
     next_lesson_uuid?: string;
     next_exercise_uuid?: string;
 }
\ No newline at end of file
@@ -1,13 +1,13 @@
 import { EditorContent, useEditor } from '@tiptap/react';
 import StarterKit from '@tiptap/starter-kit';
-import CodeMirror from '@uiw/react-codemirror';
-import { Play } from "lucide-react";
+import CodeMirror, { EditorSelection, type ReactCodeMirrorRef } from '@uiw/react-codemirror';
+import { Play, Send, Square } from "lucide-react";
 import { motion } from "motion/react";
 import { useEffect, useMemo, useRef, useState } from "react";
 import { useTranslation } from "react-i18next";
 import { usePutMeExercisesExerciseUuid } from "~/api/generated/me/me";
 import type { MeSaveUserExerciseSubmissionRequestSubmission, ModelsExerciseCodeData, ModelsExerciseWithTranslation, ModelsUserExercise } from "~/api/generated/model";
-import type { ExecuteResponse } from '~/api/types';
+import type { ExecuteResponse, SubmissionMode } from '~/api/types';
 import codyAvatar from "~/assets/cody-256.png";
 import errorSound from "~/assets/error.mp3";
 import { Button } from "~/components/base/Button";
@@ -58,11 +58,12 @@ export default function ExerciseCode({
     return getCodeValue(hasUserSubmission ? userSubmission : exercise.code_data);
   }, [userExercise.submission, exercise.code_data]);
 
+  const codeMirrorRef = useRef<ReactCodeMirrorRef>(null);
   const previousCodeRef = useRef<string>(initialCode);
   const codeValueRef = useRef<string>(initialCode);
   const [codeValue, setCodeValue] = useState(initialCode);
 
-  const [isRunning, setIsRunning] = useState(false);
+  const [runningMode, setRunningMode] = useState<SubmissionMode | null>(null);
   const [resultTab, setResultTab] = useState<string>("console");
   const [isChatOpen, setIsChatOpen] = useState(false);
 
@@ -72,7 +73,21 @@ export default function ExerciseCode({
   }, [initialCode]);
 
   function onSubmissionResponse(result: ExecuteResponse) {
-    setIsRunning(false);
+    setRunningMode(null);
+    if (result.cancelled) {
+      return;
+    }
+
+    if (result.failure) {
+      setResultTab("errors");
+      return;
+    }
+
+    if (result.mode === "run") {
+      setResultTab(result.stderr ? "errors" : "console");
+      return;
+    }
+
     if (result.passed) {
       onExerciseComplete(exercise.uuid, result.next_lesson_uuid, result.next_exercise_uuid);
     } else {
@@ -84,7 +99,7 @@ export default function ExerciseCode({
       setResultTab("errors");
     }
   }
-  const { submit, lastResult } = useWebSocket(onSubmissionResponse);
+  const { submit, cancel, lastResult, jobEvent } = useWebSocket(onSubmissionResponse);
 
   const readOnlyLines: number[] = [];
 
@@ -143,10 +158,25 @@ export default function ExerciseCode({
     codeValueRef.current = value;
   };
 
-  const handleRunCode = () => {
+  const handleGoToLine = (line: number, column = 1) => {
+    const view = codeMirrorRef.current?.view;
+    if (!view || line > view.state.doc.lines) {
+      return;
+    }
+
+    const docLine = view.state.doc.line(line);
+    const pos = Math.min(docLine.from + column - 1, docLine.to);
+    view.dispatch({
+      selection: EditorSelection.cursor(pos),
+      scrollIntoView: true,
+    });
+    view.focus();
+  };
+
+  const handleSubmitCode = (mode: SubmissionMode) => {
     const s = getSubmissionFromCode(codeValue, language);
-    setIsRunning(true);
-    submit(exercise.uuid, s);
+    setRunningMode(mode);
+    submit(exercise.uuid, s, mode);
   };
 
   return (
@@ -161,13 +191,27 @@ export default function ExerciseCode({
       </div>
       <div className="flex-1 h-full flex flex-col gap-2">
         <motion.div className="flex justify-end gap-2" variants={blurInVariants(0.5)} initial="hidden" animate="visible">
-          <Button variant="outline" onClick={handleRunCode} isLoading={isRunning} disabled={Boolean(userExercise.completed_at)}>
+          {runningMode && jobEvent && (
+            <>
+              <span className="self-center text-xs text-muted-foreground">{t(`jobStatus.${jobEvent.status}`)}</span>
+              <Button variant="ghost" onClick={() => cancel(jobEvent.job_id)}>
+                {t("common.cancel")}
+                <Square className="size-4" />
+              </Button>
+            </>
+          )}
+          <Button variant="outline" onClick={() => handleSubmitCode("run")} isLoading={runningMode === "run"} disabled={Boolean(runningMode)}>
             {t("common.run")}
             <Play className="size-4" />
           </Button>
+          <Button onClick={() => handleSubmitCode("submit")} isLoading={runningMode === "submit"} disabled={Boolean(runningMode) || Boolean(userExercise.completed_at)}>
+            {t("common.submit")}
+            <Send className="size-4" />
+          </Button>
         </motion.div>
         <motion.div className="flex flex-col flex-1 border rounded-lg overflow-hidden relative" variants={blurInVariants(0.4)} initial="hidden" animate="visible">
           <CodeMirror
+            ref={codeMirrorRef}
             dir="ltr"
             className="flex-1"
             height="100%"
@@ -177,7 +221,7 @@ export default function ExerciseCode({
             theme="light"
             readOnly={Boolean(userExercise.completed_at)}
           />
-          <ExerciseCodeResults resultTab={resultTab} setResultTab={setResultTab} lastResult={lastResult} />
+          <ExerciseCodeResults resultTab={resultTab} setResultTab={setResultTab} lastResult={lastResult} onGoToLine={handleGoToLine} />
           <img src={codyAvatar} className="size-16 absolute bottom-2 right-2 cursor-pointer hover:translate-y-[-0.25rem] transition-all duration-200" onClick={() => setIsChatOpen(!isChatOpen)} />
         </motion.div>
       </div>
@@ -1,7 +1,7 @@
 import { Ban, CheckCircle, ChevronRightSquare, FlaskConical, XCircle } from "lucide-react";
 import { motion } from "motion/react";
 import { useTranslation } from "react-i18next";
-import type { ExecuteResponse } from "~/api/types";
+import type { ExecuteResponse, ResultSet, StackFrame } from "~/api/types";
 import { useLanguage } from '~/lib/useLanguage';
 import { cn } from '~/lib/utils';
 import { blurInVariants } from "~/utils/animations";
@@ -11,12 +11,14 @@ export interface ExerciseCodeResultsProps {
   resultTab: string;
   setResultTab: (tab: string) => void;
   lastResult?: ExecuteResponse | null;
+  onGoToLine?: (line: number, column?: number) => void;
 }
 
 export default function ExerciseCodeResults({
   resultTab,
   setResultTab,
   lastResult,
+  onGoToLine,
 }: ExerciseCodeResultsProps) {
   const { t } = useTranslation();
   const { dir } = useLanguage();
@@ -25,6 +27,9 @@ export default function ExerciseCodeResults({
     return null;
   }
 
+  // The innermost frame in the learner's own code is where they should look first.
+  const errorLocation = [...(lastResult.error?.frames ?? [])].reverse().find((frame: StackFrame) => frame.user);
+
   return (
     <motion.div
       variants={blurInVariants()}
@@ -48,22 +53,64 @@ export default function ExerciseCodeResults({
           </TabsTrigger>
         </TabsList>
         <TabsContent className="text-xs px-3 font-mono" value="console">
-          <div className="whitespace-pre-wrap">
-            {lastResult.stdout || <span className="text-muted-foreground">{t("common.noOutput") || "No output"}</span>}
-          </div>
+          {lastResult.cancelled ? (
+            <div className="text-muted-foreground">{t("common.cancelled") || "Cancelled"}</div>
+          ) : lastResult.result_sets?.length ? (
+            lastResult.result_sets.map((resultSet, i) => <ResultSetTable key={i} resultSet={resultSet} />)
+          ) : (
+            <div className="whitespace-pre-wrap">
+              {lastResult.stdout || <span className="text-muted-foreground">{t("common.noOutput") || "No output"}</span>}
+            </div>
+          )}
         </TabsContent>
         <TabsContent className="text-xs px-3 font-mono" value="errors">
+          {lastResult.failure && (
+            <div className="py-1 font-sans text-red-400">
+              {t("common.executionFailed") || "The submission could not be executed"}
+            </div>
+          )}
+          {lastResult.error?.type && (
+            <div className="flex items-center gap-1.5 py-1 text-red-400">
+              <span className="font-semibold">{lastResult.error.type}: {lastResult.error.message}</span>
+              {errorLocation && (
+                <button className="text-muted-foreground underline cursor-pointer" onClick={() => onGoToLine?.(errorLocation.line, errorLocation.column)}>
+                  {errorLocation.file}:{errorLocation.line}
+                </button>
+              )}
+            </div>
+          )}
+          {lastResult.error?.explanation && (
+            <div className="py-1 font-sans text-foreground">
+              {t(lastResult.error.explanation.key, { line: "?", ...lastResult.error.explanation.params })}
+            </div>
+          )}
+          {lastResult.diagnostics?.map((diagnostic, i) => (
+            <div className={cn("whitespace-pre-wrap", diagnostic.severity === "error" ? "text-red-400" : "text-yellow-500")} key={i}>
+              {diagnostic.file}:{diagnostic.line}:{diagnostic.column} {diagnostic.code ? `TS${diagnostic.code}: ` : ""}{diagnostic.message}
+            </div>
+          ))}
           <div className="text-red-400 whitespace-pre-wrap">
-            {lastResult.stderr || <span className="text-muted-foreground">{t("common.noErrors") || "No errors"}</span>}
+            {lastResult.stderr || (!lastResult.diagnostics?.length && !lastResult.failure && <span className="text-muted-foreground">{t("common.noErrors") || "No errors"}</span>)}
           </div>
         </TabsContent>
         <TabsContent className="text-xs font-mono" value="tests">
           {lastResult.checker_results?.length === 0 ? <span className="text-muted-foreground">{t("common.noTests") || "No tests"}</span> : (
             <>
+              {lastResult.mode !== "run" && (
+                <div className="py-1 px-3 text-muted-foreground">
+                  {t("common.score") || "Score"}: {lastResult.score ?? 0}%
+                </div>
+              )}
               {lastResult.checker_results?.map((result) => (
-                <div className={cn("flex items-center gap-1.5 py-1 px-3", result.success ? "text-green-400 bg-green-50" : "text-red-400 bg-red-50")} key={result.type}>
+                <div className={cn("flex items-center gap-1.5 py-1 px-3", result.success ? "text-green-400 bg-green-50" : result.optional ? "text-yellow-500 bg-yellow-50" : "text-red-400 bg-red-50")} key={result.type}>
                   {result.success ? <CheckCircle className="size-3" /> : <XCircle className="size-3" />}
                   <span>{result.message}</span>
+                  {result.line ? <span className="text-muted-foreground">{result.file}:{result.line}</span> : null}
+                  {result.measurements?.length ? (
+                    <span className="text-muted-foreground">
+                      {result.measurements.map((m) => `n=${m.size}: ${(m.time * 1000).toFixed(1)}ms`).join(", ")}
+                    </span>
+                  ) : null}
                 </div>
               ))}
             </>
@@ -73,3 +120,33 @@ export default function ExerciseCodeResults({
     </motion.div>
   );
 }
+
+function ResultSetTable({ resultSet }: { resultSet: ResultSet }) {
+  return (
+    <table className="my-2 border-collapse">
+      <thead>
+        <tr>
+          {resultSet.columns.map((column, i) => (
+            <th className="border px-2 py-0.5 text-start font-semibold" key={i}>{column}</th>
+          ))}
+        </tr>
+      </thead>
+      <tbody>
+        {resultSet.rows.map((row, i) => (
+          <tr key={i}>
+            {row.map((value, j) => (
+              <td className="border px-2 py-0.5" key={j}>
+                {value === null ? <span className="text-muted-foreground">NULL</span> : String(value)}
+              </td>
+            ))}
+          </tr>
+        ))}
+        {resultSet.truncated && (
+          <tr>
+            <td className="border px-2 py-0.5 text-muted-foreground" colSpan={resultSet.columns.length}>…</td>
+          </tr>
+        )}
+      </tbody>
+    </table>
+  );
+}
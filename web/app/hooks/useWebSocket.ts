@@ -1,10 +1,14 @@
 import { useCallback, useEffect, useRef, useState } from 'react';
 import type { ModelsExerciseCodeData } from '~/api/generated/model';
-import type { ExecuteResponse, UserExerciseQuizData } from '~/api/types';
+import type { ExecuteResponse, JobEvent, JobStatus, SubmissionMode, UserExerciseQuizData } from '~/api/types';
+
+// Events may arrive out of order, a job never goes back to an earlier status.
+const JOB_STATUS_ORDER: JobStatus[] = ["queued", "started", "finished"];
 
 
 export const useWebSocket = (onSubmissionResponse?: (result: ExecuteResponse) => void) => {
   const [lastResult, setLastResult] = useState<ExecuteResponse | null>(null);
+  const [jobEvent, setJobEvent] = useState<JobEvent | null>(null);
   const [isConnected, setIsConnected] = useState(false);
   const socketRef = useRef<WebSocket | null>(null);
 
@@ -34,8 +38,18 @@ export const useWebSocket = (onSubmissionResponse?: (result: ExecuteResponse) =>
         if (!isMounted) return;
         try {
           const response = JSON.parse(event.data);
+          if (response.type === "job_event") {
+            setJobEvent((current) =>
+              current?.job_id === response.job_id && JOB_STATUS_ORDER.indexOf(current.status) > JOB_STATUS_ORDER.indexOf(response.status)
+                ? current
+                : response
+            );
+            return;
+          }
+
           // Check if it looks like ExecuteResponse
           if (response.job_id) {
+            setJobEvent(null);
             setLastResult(response);
             onSubmissionResponse?.(response);
           }
@@ -74,13 +88,20 @@ export const useWebSocket = (onSubmissionResponse?: (result: ExecuteResponse) =>
     };
   }, []);
 
-  const submit = useCallback((exerciseUuid: string, submission: ModelsExerciseCodeData | UserExerciseQuizData) => {
+  const submit = useCallback((exerciseUuid: string, submission: ModelsExerciseCodeData | UserExerciseQuizData, mode: SubmissionMode = "submit") => {
     if (socketRef.current && socketRef.current.readyState === WebSocket.OPEN) {
-      socketRef.current.send(JSON.stringify({ "exercise_uuid": exerciseUuid, "submission": submission }));
+      setJobEvent(null);
+      socketRef.current.send(JSON.stringify({ "exercise_uuid": exerciseUuid, "submission": submission, "mode": mode }));
     } else {
       console.error('WebSocket is not connected');
     }
   }, []);
 
-  return { submit, lastResult, isConnected };
+  const cancel = useCallback((jobId: string) => {
+    if (socketRef.current && socketRef.current.readyState === WebSocket.OPEN) {
+      socketRef.current.send(JSON.stringify({ "type": "cancel", "job_id": jobId }));
+    }
+  }, []);
+
+  return { submit, cancel, lastResult, jobEvent, isConnected };
 };
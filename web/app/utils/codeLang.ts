@@ -3,6 +3,9 @@ import type { LanguageName } from "@uiw/codemirror-extensions-langs";
 const LANGUAGE_MAP: Record<string, LanguageName> = {
   "javascript": "js",
   "python": "py",
+  "sql": "sql",
+  "bash": "sh",
+  "typescript": "ts",
 };
 
 export default LANGUAGE_MAP;
\ No newline at end of file